@@ -17,10 +17,14 @@
 package SpringEcho
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -42,6 +46,14 @@ type Context struct {
 
 	// handlerFunc Web 处理函数
 	handlerFunc SpringWeb.Handler
+
+	// writer 记录响应状态的 http.ResponseWriter，echo.Response 写入的也是它
+	writer *responseWriter
+}
+
+// SetLoggerContext 设置日志接口上下文
+func (ctx *Context) SetLoggerContext(logCtx SpringLogger.LoggerContext) {
+	ctx.LoggerContext = logCtx
 }
 
 // NativeContext 返回封装的底层上下文对象
@@ -64,6 +76,11 @@ func (ctx *Context) Request() *http.Request {
 	return ctx.echoContext.Request()
 }
 
+// SetRequest sets `*http.Request`.
+func (ctx *Context) SetRequest(r *http.Request) {
+	ctx.echoContext.SetRequest(r)
+}
+
 // IsTLS returns true if HTTP connection is TLS otherwise false.
 func (ctx *Context) IsTLS() bool {
 	return ctx.echoContext.IsTLS()
@@ -76,12 +93,12 @@ func (ctx *Context) IsWebSocket() bool {
 
 // Scheme returns the HTTP protocol scheme, `http` or `https`.
 func (ctx *Context) Scheme() string {
-	return ctx.echoContext.Scheme()
+	return SpringWeb.Scheme(ctx)
 }
 
-// ClientIP implements a best effort algorithm to return the real client IP.
+// ClientIP returns the real client IP, trusting only the container's proxies.
 func (ctx *Context) ClientIP() string {
-	return ctx.echoContext.RealIP()
+	return SpringWeb.ClientIP(ctx)
 }
 
 // Path returns the registered path for the handler.
@@ -206,9 +223,58 @@ func (ctx *Context) Bind(i interface{}) error {
 	return ctx.echoContext.Bind(i)
 }
 
+// responseWriter 返回当前记录响应状态的 http.ResponseWriter，第一次调用时使用
+// echo.Response 已经记录的状态
+func (ctx *Context) responseWriter() *responseWriter {
+	if ctx.writer == nil {
+		resp := ctx.echoContext.Response()
+		ctx.writer = &responseWriter{
+			writer:    resp.Writer,
+			status:    resp.Status,
+			size:      int(resp.Size),
+			committed: resp.Committed,
+		}
+		if ctx.writer.status == 0 {
+			ctx.writer.status = http.StatusOK
+		}
+		resp.Writer = ctx.writer
+	}
+	return ctx.writer
+}
+
 // ResponseWriter returns `http.ResponseWriter`.
 func (ctx *Context) ResponseWriter() http.ResponseWriter {
-	return ctx.echoContext.Response().Writer
+	return ctx.responseWriter()
+}
+
+// SetResponseWriter sets the `http.ResponseWriter` used by the following
+// filters and the handler, usually a wrapper of ResponseWriter().
+func (ctx *Context) SetResponseWriter(w http.ResponseWriter) {
+	prev := ctx.responseWriter()
+	if resp, ok := w.(*echo.Response); ok {
+		w = resp.Writer
+	}
+	rw, ok := w.(*responseWriter)
+	if !ok {
+		rw = &responseWriter{writer: w, status: prev.status}
+	}
+	ctx.writer = rw
+	ctx.echoContext.Response().Writer = rw
+}
+
+// ResponseStatus returns the HTTP response status code set so far.
+func (ctx *Context) ResponseStatus() int {
+	return ctx.responseWriter().status
+}
+
+// ResponseSize returns the number of body bytes written so far.
+func (ctx *Context) ResponseSize() int {
+	return ctx.responseWriter().size
+}
+
+// Written returns true if the response header was already written.
+func (ctx *Context) Written() bool {
+	return ctx.responseWriter().committed
 }
 
 // Status sets the HTTP response code.
@@ -280,6 +346,18 @@ func (ctx *Context) JSONPBlob(code int, callback string, b []byte) {
 	SpringUtils.Panic(err).When(err != nil)
 }
 
+// Problem sends an RFC 7807 `application/problem+json` response with
+// the status code of the problem.
+func (ctx *Context) Problem(p *SpringWeb.Problem) {
+	code := p.Status
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	b, err := json.Marshal(p)
+	SpringUtils.Panic(err).When(err != nil)
+	ctx.Blob(code, SpringWeb.MIMEApplicationProblemJSON, b)
+}
+
 // XML sends an XML response with status code.
 func (ctx *Context) XML(code int, i interface{}) {
 	err := ctx.echoContext.XML(code, i)
@@ -338,3 +416,52 @@ func (ctx *Context) Redirect(code int, url string) {
 func (ctx *Context) SSEvent(name string, message interface{}) {
 	panic(SpringConst.UnimplementedMethod)
 }
+
+// responseWriter 记录响应状态的 http.ResponseWriter，过滤器或者处理函数直接写入的
+// 数据也能得到和 echo.Response 一样的状态
+type responseWriter struct {
+	writer    http.ResponseWriter
+	status    int
+	size      int
+	committed bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.writer.Header()
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.committed {
+		return
+	}
+	w.status = code
+	w.committed = true
+	w.writer.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (n int, err error) {
+	if !w.committed {
+		w.WriteHeader(w.status)
+	}
+	n, err = w.writer.Write(b)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.writer.(http.Flusher); ok {
+		if !w.committed {
+			w.WriteHeader(w.status)
+		}
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.writer.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker is not implemented")
+	}
+	w.committed = true
+	return h.Hijack()
+}
@@ -56,12 +56,34 @@ func (c *Container) Start() {
 		e.HideBanner = true
 	}
 
+	// 路径修正、404 和 405 由 Spring-Web 统一处理
+	filters := c.ChainFilters(nil, "")
+	notFound := HandlerWrapper(c.GetNotFoundHandler(), filters)
+	methodNotAllowed := HandlerWrapper(c.MethodNotAllowed, filters)
+	errorHandler := c.echoServer.HTTPErrorHandler
+	c.echoServer.HTTPErrorHandler = func(err error, echoCtx echo.Context) {
+		switch err {
+		case echo.ErrNotFound:
+			if !c.fixPath(echoCtx) {
+				echoCtx.SetPath("")
+				_ = notFound(echoCtx)
+			}
+		case echo.ErrMethodNotAllowed:
+			if !c.fixPath(echoCtx) {
+				echoCtx.SetPath("")
+				_ = methodNotAllowed(echoCtx)
+			}
+		default:
+			errorHandler(err, echoCtx)
+		}
+	}
+
 	// 映射 Web 处理函数
 	for _, mapper := range c.Mappers() {
-		filters := append(c.GetFilters(), mapper.Filters()...)
-		handler := HandlerWrapper(mapper.Handler(), filters)
 		path := SpringWeb.PathConvert(mapper.Path())
 		for _, method := range SpringWeb.GetMethod(mapper.Method()) {
+			filters := c.ChainFilters(mapper, method)
+			handler := HandlerWrapper(mapper.Handler(), filters)
 			c.echoServer.Add(method, path, handler)
 		}
 	}
@@ -79,6 +101,23 @@ func (c *Container) Start() {
 	}()
 }
 
+// fixPath 按照容器的路径策略修正请求路径，返回 true 表示请求已经处理
+func (c *Container) fixPath(echoCtx echo.Context) bool {
+	r := echoCtx.Request()
+	path, redirect := c.FixPath(r)
+	if path == "" {
+		return false
+	}
+	if redirect {
+		SpringWeb.RedirectPath(echoCtx.Response(), r, path)
+		return true
+	}
+	r.URL.Path = path
+	r.URL.RawPath = ""
+	c.echoServer.ServeHTTP(echoCtx.Response().Writer, r)
+	return true
+}
+
 // Stop 停止 Web 容器，阻塞
 func (c *Container) Stop(ctx context.Context) {
 	err := c.echoServer.Shutdown(ctx)
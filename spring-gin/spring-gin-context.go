@@ -17,11 +17,13 @@
 package SpringGin
 
 import (
+	"bufio"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -58,6 +60,11 @@ type Context struct {
 	pathParamValues []string
 }
 
+// SetLoggerContext 设置日志接口上下文
+func (ctx *Context) SetLoggerContext(logCtx SpringLogger.LoggerContext) {
+	ctx.LoggerContext = logCtx
+}
+
 // NativeContext 返回封装的底层上下文对象
 func (ctx *Context) NativeContext() interface{} {
 	return ctx.ginContext
@@ -79,6 +86,11 @@ func (ctx *Context) Request() *http.Request {
 	return ctx.ginContext.Request
 }
 
+// SetRequest sets `*http.Request`.
+func (ctx *Context) SetRequest(r *http.Request) {
+	ctx.ginContext.Request = r
+}
+
 // IsTLS returns true if HTTP connection is TLS otherwise false.
 func (ctx *Context) IsTLS() bool {
 	return ctx.ginContext.Request.TLS != nil
@@ -91,37 +103,12 @@ func (ctx *Context) IsWebSocket() bool {
 
 // Scheme returns the HTTP protocol scheme, `http` or `https`.
 func (ctx *Context) Scheme() string {
-	// NOTE: 这一段逻辑使用 echo 的实现
-	r := ctx.Request()
-
-	// Can't use `r.Request.URL.Scheme`
-	// See: https://groups.google.com/forum/#!topic/golang-nuts/pMUkBlQBDF0
-
-	if r.TLS != nil {
-		return "https"
-	}
-
-	if scheme := r.Header.Get(SpringWeb.HeaderXForwardedProto); scheme != "" {
-		return scheme
-	}
-
-	if scheme := r.Header.Get(SpringWeb.HeaderXForwardedProtocol); scheme != "" {
-		return scheme
-	}
-
-	if ssl := r.Header.Get(SpringWeb.HeaderXForwardedSsl); ssl == "on" {
-		return "https"
-	}
-
-	if scheme := r.Header.Get(SpringWeb.HeaderXUrlScheme); scheme != "" {
-		return scheme
-	}
-	return "http"
+	return SpringWeb.Scheme(ctx)
 }
 
-// ClientIP implements a best effort algorithm to return the real client IP
+// ClientIP returns the real client IP, trusting only the container's proxies.
 func (ctx *Context) ClientIP() string {
-	return ctx.ginContext.ClientIP()
+	return SpringWeb.ClientIP(ctx)
 }
 
 // Path returns the registered path for the handler.
@@ -152,13 +139,9 @@ func (ctx *Context) GetRawData() ([]byte, error) {
 // PathParam returns path parameter by name.
 func (ctx *Context) PathParam(name string) string {
 	if name == "*" {
-		name = WildRouteName
+		return strings.TrimPrefix(ctx.ginContext.Param(WildRouteName), "/")
 	}
-	v := ctx.ginContext.Param(name)
-	if len(v) > 0 {
-		return v[1:]
-	}
-	return ""
+	return ctx.ginContext.Param(name)
 }
 
 // PathParamNames returns path parameter names.
@@ -182,8 +165,8 @@ func (ctx *Context) PathParamValues() []string {
 		ctx.pathParamValues = make([]string, 0)
 		for _, entry := range ctx.ginContext.Params {
 			v := entry.Value
-			if len(v) > 0 {
-				v = v[1:]
+			if entry.Key == WildRouteName {
+				v = strings.TrimPrefix(v, "/")
 			}
 			ctx.pathParamValues = append(ctx.pathParamValues, v)
 		}
@@ -256,7 +239,8 @@ func (ctx *Context) Cookies() []*http.Cookie {
 
 // Bind binds the request body into provided type `i`.
 func (ctx *Context) Bind(i interface{}) error {
-	return ctx.ginContext.Bind(i)
+	// 和 echo 保持一致，绑定失败时不写入响应
+	return ctx.ginContext.ShouldBind(i)
 }
 
 // ResponseWriter returns `http.ResponseWriter`.
@@ -264,6 +248,34 @@ func (ctx *Context) ResponseWriter() http.ResponseWriter {
 	return ctx.ginContext.Writer
 }
 
+// SetResponseWriter sets the `http.ResponseWriter` used by the following
+// filters and the handler, usually a wrapper of ResponseWriter().
+func (ctx *Context) SetResponseWriter(w http.ResponseWriter) {
+	if rw, ok := w.(gin.ResponseWriter); ok {
+		ctx.ginContext.Writer = rw
+		return
+	}
+	ctx.ginContext.Writer = newResponseWriter(ctx.ginContext.Writer, w)
+}
+
+// ResponseStatus returns the HTTP response status code set so far.
+func (ctx *Context) ResponseStatus() int {
+	return ctx.ginContext.Writer.Status()
+}
+
+// ResponseSize returns the number of body bytes written so far.
+func (ctx *Context) ResponseSize() int {
+	if size := ctx.ginContext.Writer.Size(); size > 0 {
+		return size
+	}
+	return 0
+}
+
+// Written returns true if the response header was already written.
+func (ctx *Context) Written() bool {
+	return ctx.ginContext.Writer.Written()
+}
+
 // Status sets the HTTP response code.
 func (ctx *Context) Status(code int) {
 	ctx.ginContext.Status(code)
@@ -282,6 +294,7 @@ func (ctx *Context) SetCookie(cookie *http.Cookie) {
 // NoContent sends a response with no body and a status code.
 func (ctx *Context) NoContent(code int) {
 	ctx.Status(code)
+	ctx.ginContext.Writer.WriteHeaderNow()
 }
 
 // String writes the given string into the response body.
@@ -374,6 +387,18 @@ func (ctx *Context) JSONPBlob(code int, callback string, b []byte) {
 	SpringUtils.Panic(err).When(err != nil)
 }
 
+// Problem sends an RFC 7807 `application/problem+json` response with
+// the status code of the problem.
+func (ctx *Context) Problem(p *SpringWeb.Problem) {
+	code := p.Status
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	b, err := json.Marshal(p)
+	SpringUtils.Panic(err).When(err != nil)
+	ctx.Blob(code, SpringWeb.MIMEApplicationProblemJSON, b)
+}
+
 // XML sends an XML response with status code.
 func (ctx *Context) XML(code int, i interface{}) {
 	ctx.ginContext.XML(code, i)
@@ -477,3 +502,81 @@ func (ctx *Context) Redirect(code int, url string) {
 func (ctx *Context) SSEvent(name string, message interface{}) {
 	ctx.ginContext.SSEvent(name, message)
 }
+
+// responseWriter 使用过滤器设置的 http.ResponseWriter 实现 gin.ResponseWriter
+type responseWriter struct {
+	gin.ResponseWriter // 原来的 gin.ResponseWriter，用于 Hijack 等操作
+
+	writer http.ResponseWriter // 过滤器设置的 http.ResponseWriter
+	status int
+	size   int // -1 表示还没有写入响应头
+}
+
+// newResponseWriter responseWriter 的构造函数
+func newResponseWriter(prev gin.ResponseWriter, w http.ResponseWriter) *responseWriter {
+	return &responseWriter{
+		ResponseWriter: prev,
+		writer:         w,
+		status:         prev.Status(),
+		size:           -1,
+	}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.writer.Header()
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.writer.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = w.writer.Write(data)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) WriteString(s string) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = io.WriteString(w.writer, s)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != -1
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.size < 0 {
+		w.size = 0
+	}
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if f, ok := w.writer.(http.Flusher); ok {
+		f.Flush()
+	} else {
+		w.ResponseWriter.Flush()
+	}
+}
@@ -62,12 +62,20 @@ func (c *Container) Start() {
 		c.ginEngine = gin.New()
 	}
 
+	// 路径修正、404 和 405 由 Spring-Web 统一处理
+	c.ginEngine.RedirectTrailingSlash = false
+	c.ginEngine.RedirectFixedPath = false
+	c.ginEngine.HandleMethodNotAllowed = true
+	filters := c.ChainFilters(nil, "")
+	c.ginEngine.NoRoute(c.fixPathWrapper(HandlerWrapper("", c.GetNotFoundHandler(), filters)))
+	c.ginEngine.NoMethod(c.fixPathWrapper(HandlerWrapper("", c.MethodNotAllowed, filters)))
+
 	for _, mapper := range c.Mappers() {
 		path := SpringWeb.PathConvert(mapper.Path())
-		filters := append(c.GetFilters(), mapper.Filters()...)
-		handler := HandlerWrapper(mapper.Path(), mapper.Handler(), filters)
+		path = strings.Replace(path, "*", "*"+WildRouteName, 1)
 		for _, method := range SpringWeb.GetMethod(mapper.Method()) {
-			path := strings.Replace(path, "*", "*"+WildRouteName, 1)
+			filters := c.ChainFilters(mapper, method)
+			handler := HandlerWrapper(mapper.Path(), mapper.Handler(), filters)
 			c.ginEngine.Handle(method, path, handler)
 		}
 	}
@@ -89,6 +97,26 @@ func (c *Container) Start() {
 	}()
 }
 
+// fixPathWrapper 没有匹配到路由时先尝试按照容器的路径策略修正请求路径
+func (c *Container) fixPathWrapper(fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		r := ginCtx.Request
+		path, redirect := c.FixPath(r)
+		if path == "" {
+			fn(ginCtx)
+			return
+		}
+		if redirect {
+			SpringWeb.RedirectPath(ginCtx.Writer, r, path)
+			return
+		}
+		r.URL.Path = path
+		r.URL.RawPath = ""
+		ginCtx.Status(http.StatusOK) // 清除 gin 预设的 404 或 405 状态码
+		c.ginEngine.HandleContext(ginCtx)
+	}
+}
+
 // Stop 停止 Web 容器，阻塞
 func (c *Container) Stop(ctx context.Context) {
 	err := c.httpServer.Shutdown(ctx)
@@ -110,7 +138,13 @@ func HandlerWrapper(path string, fn SpringWeb.Handler, filters []SpringWeb.Filte
 			handlerFunc:   fn,
 		}
 
+		writer := ginCtx.Writer
 		SpringWeb.InvokeHandler(webCtx, fn, filters)
+
+		// gin 只会为原始的 ResponseWriter 补写响应头，过滤器设置的 ResponseWriter
+		// 可能只向原始的 ResponseWriter 传递了状态码而没有写入响应体
+		ginCtx.Writer.WriteHeaderNow()
+		writer.WriteHeaderNow()
 	}
 }
 
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AccessLogFormat 访问日志的格式
+type AccessLogFormat int
+
+const (
+	AccessLogCombined AccessLogFormat = iota // Apache combined 格式，末尾追加耗时、路由和请求 ID
+	AccessLogJSON                            // 每行一个 JSON 对象
+)
+
+// AccessLogEntry 一条访问日志
+type AccessLogEntry struct {
+	Time      time.Time     `json:"time"`
+	Method    string        `json:"method"`
+	Path      string        `json:"path"` // 注册的路由路径
+	URI       string        `json:"uri"`  // 原始的请求地址
+	Proto     string        `json:"proto"`
+	Status    int           `json:"status"`
+	Latency   time.Duration `json:"latency"` // 单位纳秒
+	Bytes     int           `json:"bytes"`
+	ClientIP  string        `json:"client_ip"`
+	RequestID string        `json:"request_id,omitempty"`
+	Referer   string        `json:"referer,omitempty"`
+	UserAgent string        `json:"user_agent,omitempty"`
+}
+
+// Combined 返回 Apache combined 格式的日志
+func (e *AccessLogEntry) Combined() string {
+	bytes := "-"
+	if e.Bytes > 0 {
+		bytes = strconv.Itoa(e.Bytes)
+	}
+	requestID := e.RequestID
+	if requestID == "" {
+		requestID = "-"
+	}
+	return fmt.Sprintf("%s - - [%s] %q %d %s %q %q %.3fms %q %s",
+		e.ClientIP, e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.Method+" "+e.URI+" "+e.Proto, e.Status, bytes, e.Referer, e.UserAgent,
+		float64(e.Latency)/float64(time.Millisecond), e.Path, requestID)
+}
+
+// JSON 返回 JSON 格式的日志
+func (e *AccessLogEntry) JSON() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+// AccessLogFilter 访问日志过滤器，默认使用 Apache combined 格式通过 SpringLogger 输出
+type AccessLogFilter struct {
+	format     AccessLogFormat
+	sampleRate float64  // 采样率，5xx 响应总是输出
+	skipPaths  []string // 不输出日志的路径，支持 Ant 风格的模式
+	output     func(ctx WebContext, entry *AccessLogEntry, line string)
+}
+
+// NewAccessLogFilter AccessLogFilter 的构造函数
+func NewAccessLogFilter() *AccessLogFilter {
+	return &AccessLogFilter{
+		format:     AccessLogCombined,
+		sampleRate: 1,
+		output: func(ctx WebContext, _ *AccessLogEntry, line string) {
+			ctx.LogInfo(line)
+		},
+	}
+}
+
+// WithFormat 设置日志格式
+func (f *AccessLogFilter) WithFormat(format AccessLogFormat) *AccessLogFilter {
+	f.format = format
+	return f
+}
+
+// WithSampleRate 设置采样率，取值范围 [0,1]，5xx 响应不受采样率限制
+func (f *AccessLogFilter) WithSampleRate(rate float64) *AccessLogFilter {
+	f.sampleRate = rate
+	return f
+}
+
+// Skip 设置不输出日志的路径，例如健康检查接口，支持 Ant 风格的模式
+func (f *AccessLogFilter) Skip(patterns ...string) *AccessLogFilter {
+	f.skipPaths = append(f.skipPaths, patterns...)
+	return f
+}
+
+// WithOutput 设置日志的输出方式，默认使用 ctx.LogInfo 输出
+func (f *AccessLogFilter) WithOutput(fn func(ctx WebContext, entry *AccessLogEntry, line string)) *AccessLogFilter {
+	f.output = fn
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *AccessLogFilter) Name() string {
+	return "accessLog"
+}
+
+func (f *AccessLogFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	r := ctx.Request()
+
+	for _, pattern := range f.skipPaths {
+		if AntMatch(pattern, r.URL.Path) {
+			chain.Next(ctx)
+			return
+		}
+	}
+
+	start := time.Now()
+
+	// panic 由外层恢复，这里按照 500 输出日志后继续向外传递
+	panicked := true
+	defer func() {
+		status := ctx.ResponseStatus()
+		if panicked {
+			status = http.StatusInternalServerError
+		}
+		f.log(ctx, start, status)
+	}()
+
+	chain.Next(ctx)
+	panicked = false
+}
+
+// log 输出一次请求的访问日志
+func (f *AccessLogFilter) log(ctx WebContext, start time.Time, status int) {
+	r := ctx.Request()
+	if status < 500 && f.sampleRate < 1 && rand.Float64() >= f.sampleRate {
+		return
+	}
+
+	entry := &AccessLogEntry{
+		Time:      start,
+		Method:    r.Method,
+		Path:      ctx.Path(),
+		URI:       r.RequestURI,
+		Proto:     r.Proto,
+		Status:    status,
+		Latency:   time.Since(start),
+		Bytes:     ctx.ResponseSize(),
+		ClientIP:  ctx.ClientIP(),
+		RequestID: RequestID(ctx),
+		Referer:   r.Referer(),
+		UserAgent: r.UserAgent(),
+	}
+	if entry.URI == "" {
+		entry.URI = r.URL.RequestURI()
+	}
+
+	var line string
+	if f.format == AccessLogJSON {
+		line = entry.JSON()
+	} else {
+		line = entry.Combined()
+	}
+	f.output(ctx, entry, line)
+}
@@ -0,0 +1,341 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// PrincipalKey 认证结果在 WebContext 中的 key
+const PrincipalKey = "::SpringWeb::Principal"
+
+// ErrInvalidCredentials 凭证无效
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authentication 认证结果
+type Authentication struct {
+	Name   string                 // 用户名称
+	Scheme string                 // 认证方式，例如 Basic、ApiKey、Bearer
+	Roles  []string               // 角色
+	Scopes []string               // 授权范围
+	Claims map[string]interface{} // 其他属性
+}
+
+// HasRole 是否具有指定的角色
+func (a *Authentication) HasRole(role string) bool {
+	return containsString(a.Roles, role)
+}
+
+// HasScope 是否具有指定的授权范围
+func (a *Authentication) HasScope(scope string) bool {
+	return containsString(a.Scopes, scope)
+}
+
+// Principal 返回当前请求的认证结果，没有认证时返回 nil
+func Principal(ctx WebContext) *Authentication {
+	a, _ := ctx.Get(PrincipalKey).(*Authentication)
+	return a
+}
+
+// SetPrincipal 设置当前请求的认证结果
+func SetPrincipal(ctx WebContext, a *Authentication) {
+	ctx.Set(PrincipalKey, a)
+}
+
+// Authenticator 认证器
+type Authenticator interface {
+	// Authenticate 请求中没有该方式的凭证时返回 nil, nil，凭证无效时返回 error
+	Authenticate(ctx WebContext) (*Authentication, error)
+}
+
+// SecurityDefinition 可以提供 swagger 安全定义的认证器，AuthFilter 会通过全局 swagger
+// 对象的 Add*SecurityDefinition 方法注册安全定义，以便文档和实际的认证方式保持一致
+type SecurityDefinition interface {
+	// AddSecurityDefinition 注册安全定义并返回它的名称，返回空字符串表示没有注册
+	AddSecurityDefinition() string
+}
+
+// Challenger 可以提供 WWW-Authenticate 响应头的认证器
+type Challenger interface {
+	// Challenge 返回 WWW-Authenticate 响应头的值
+	Challenge() string
+}
+
+// AuthFilter 依次使用多个认证器进行认证的过滤器，第一个认证成功的结果通过 Principal(ctx)
+// 获取。凭证无效或者要求认证但是没有凭证时通过 RenderError 返回 401。
+type AuthFilter struct {
+	authenticators []Authenticator
+	securities     []string // 已注册的 swagger 安全定义的名称
+	optional       bool
+}
+
+// NewAuthFilter AuthFilter 的构造函数，同时注册认证器的 swagger 安全定义
+func NewAuthFilter(authenticators ...Authenticator) *AuthFilter {
+	var securities []string
+	for _, a := range authenticators {
+		if d, ok := a.(SecurityDefinition); ok {
+			if name := d.AddSecurityDefinition(); name != "" {
+				securities = append(securities, name)
+			}
+		}
+	}
+	return &AuthFilter{authenticators: authenticators, securities: securities}
+}
+
+// authenticationFilter 认证过滤器，提供 swagger 安全定义的名称和 WWW-Authenticate
+// 响应头的值，供路由的权限检查和 swagger 文档使用
+type authenticationFilter interface {
+	securityNames() []string
+	challenges() []string
+}
+
+func (f *AuthFilter) securityNames() []string {
+	return f.securities
+}
+
+func (f *AuthFilter) challenges() []string {
+	var result []string
+	for _, a := range f.authenticators {
+		if c, ok := a.(Challenger); ok {
+			result = append(result, c.Challenge())
+		}
+	}
+	return result
+}
+
+// WithSecurity 添加认证器以外注册的 swagger 安全定义，例如签发 Token 的 oauth2 服务
+func (f *AuthFilter) WithSecurity(names ...string) *AuthFilter {
+	for _, name := range names {
+		if !containsString(f.securities, name) {
+			f.securities = append(f.securities, name)
+		}
+	}
+	return f
+}
+
+// Optional 设置是否允许匿名访问，允许时没有凭证的请求也会继续执行
+func (f *AuthFilter) Optional(optional bool) *AuthFilter {
+	f.optional = optional
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *AuthFilter) Name() string {
+	return "auth"
+}
+
+func (f *AuthFilter) Invoke(ctx WebContext, chain *FilterChain) {
+
+	for _, a := range f.authenticators {
+		auth, err := a.Authenticate(ctx)
+		if err != nil {
+			f.unauthorized(ctx, err)
+			return
+		}
+		if auth != nil {
+			SetPrincipal(ctx, auth)
+			chain.Next(ctx)
+			return
+		}
+	}
+
+	if f.optional {
+		chain.Next(ctx)
+		return
+	}
+	f.unauthorized(ctx, errors.New("authentication required"))
+}
+
+// unauthorized 返回 401
+func (f *AuthFilter) unauthorized(ctx WebContext, err error) {
+	unauthorized(ctx, f.challenges(), err)
+}
+
+// unauthorized 返回带有 WWW-Authenticate 响应头的 401
+func unauthorized(ctx WebContext, challenges []string, err error) {
+	for _, c := range challenges {
+		ctx.ResponseWriter().Header().Add(HeaderWWWAuthenticate, c)
+	}
+	RenderError(ctx, NewProblem(http.StatusUnauthorized).WithDetail(err.Error()))
+}
+
+// authorizeFilter 检查认证结果是否具有路由要求的全部角色或者授权范围
+type authorizeFilter struct {
+	authorities []string
+	challenges  []string // 路由的认证过滤器的 WWW-Authenticate 响应头的值
+}
+
+// newAuthorizeFilter authorizeFilter 的构造函数，使用 filters 中认证过滤器的认证方式
+func newAuthorizeFilter(authorities []string, filters []Filter) *authorizeFilter {
+	f := &authorizeFilter{authorities: authorities}
+	for _, filter := range filters {
+		if af, ok := filter.(authenticationFilter); ok {
+			for _, c := range af.challenges() {
+				if !containsString(f.challenges, c) {
+					f.challenges = append(f.challenges, c)
+				}
+			}
+		}
+	}
+	return f
+}
+
+func (f *authorizeFilter) Invoke(ctx WebContext, chain *FilterChain) {
+
+	auth := Principal(ctx)
+	if auth == nil {
+		unauthorized(ctx, f.challenges, errors.New("authentication required"))
+		return
+	}
+
+	for _, s := range f.authorities {
+		if !auth.HasRole(s) && !auth.HasScope(s) {
+			RenderError(ctx, NewProblem(http.StatusForbidden).WithDetail("missing authority "+s))
+			return
+		}
+	}
+	chain.Next(ctx)
+}
+
+// authScheme 内置认证器的认证方式
+type authScheme string
+
+// check 检查凭证校验函数的结果，校验函数返回 nil, nil 时认为凭证无效
+func (s authScheme) check(auth *Authentication, err error) (*Authentication, error) {
+	if err != nil {
+		return nil, err
+	}
+	if auth == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if auth.Scheme == "" {
+		auth.Scheme = string(s)
+	}
+	return auth, nil
+}
+
+// BasicAuthenticator HTTP Basic 认证器
+type BasicAuthenticator struct {
+	realm    string
+	validate func(username, password string) (*Authentication, error)
+}
+
+// NewBasicAuthenticator BasicAuthenticator 的构造函数
+func NewBasicAuthenticator(realm string, fn func(username, password string) (*Authentication, error)) *BasicAuthenticator {
+	return &BasicAuthenticator{realm: realm, validate: fn}
+}
+
+func (a *BasicAuthenticator) Authenticate(ctx WebContext) (*Authentication, error) {
+	username, password, ok := ctx.Request().BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	return authScheme("Basic").check(a.validate(username, password))
+}
+
+// AddSecurityDefinition 通过 AddBasicSecurityDefinition 注册安全定义
+func (a *BasicAuthenticator) AddSecurityDefinition() string {
+	doc.AddBasicSecurityDefinition()
+	return "BasicAuth"
+}
+
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="` + strings.Replace(a.realm, `"`, `\"`, -1) + `"`
+}
+
+// APIKeyAuthenticator API Key 认证器，API Key 可以位于请求头、查询参数或者 Cookie 中
+type APIKeyAuthenticator struct {
+	name     string
+	in       string
+	validate func(key string) (*Authentication, error)
+}
+
+// NewAPIKeyAuthenticator APIKeyAuthenticator 的构造函数，in 可以是 header、query 和 cookie
+func NewAPIKeyAuthenticator(name string, in string, fn func(key string) (*Authentication, error)) *APIKeyAuthenticator {
+	if in != "header" && in != "query" && in != "cookie" {
+		panic(errors.New("api key must be in header, query or cookie"))
+	}
+	return &APIKeyAuthenticator{name: name, in: in, validate: fn}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx WebContext) (*Authentication, error) {
+	var key string
+	switch a.in {
+	case "header":
+		key = ctx.Request().Header.Get(a.name)
+	case "query":
+		key = ctx.QueryParam(a.name)
+	case "cookie":
+		if c, err := ctx.Cookie(a.name); err == nil {
+			key = c.Value
+		}
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return authScheme("ApiKey").check(a.validate(key))
+}
+
+// AddSecurityDefinition 通过 AddApiKeySecurityDefinition 注册安全定义，swagger 2.0
+// 不支持位于 Cookie 中的 API Key
+func (a *APIKeyAuthenticator) AddSecurityDefinition() string {
+	if a.in == "cookie" {
+		return ""
+	}
+	doc.AddApiKeySecurityDefinition(a.name, a.in)
+	return a.name
+}
+
+// BearerAuthenticator Bearer Token 认证器
+type BearerAuthenticator struct {
+	validate func(token string) (*Authentication, error)
+}
+
+// NewBearerAuthenticator BearerAuthenticator 的构造函数
+func NewBearerAuthenticator(fn func(token string) (*Authentication, error)) *BearerAuthenticator {
+	return &BearerAuthenticator{validate: fn}
+}
+
+func (a *BearerAuthenticator) Authenticate(ctx WebContext) (*Authentication, error) {
+	token, ok := BearerToken(ctx.Request())
+	if !ok {
+		return nil, nil
+	}
+	return authScheme("Bearer").check(a.validate(token))
+}
+
+// AddSecurityDefinition 通过 AddBearerSecurityDefinition 注册安全定义
+func (a *BearerAuthenticator) AddSecurityDefinition() string {
+	doc.AddBearerSecurityDefinition()
+	return "BearerAuth"
+}
+
+func (a *BearerAuthenticator) Challenge() string {
+	return "Bearer"
+}
+
+// BearerToken 返回 Authorization 请求头中的 Bearer Token
+func BearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	h := r.Header.Get(HeaderAuthorization)
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(h[len(prefix):]), true
+}
@@ -0,0 +1,339 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 支持的压缩编码，brotli 需要第三方库，客户端优先 br 时回退到 gzip 或者 deflate
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// CompressionFilter 响应压缩过滤器，根据 Accept-Encoding 选择 gzip 或者 deflate，
+// 只压缩指定 MIME 类型并且超过大小阈值的响应。已经设置 Content-Encoding 的响应、
+// 图片等已经压缩过的内容、Range 请求和 WebSocket 请求不会被压缩。处理函数调用 Flush
+// 时立即输出已经压缩的数据，因此适用于 Stream 和 SSEvent。
+type CompressionFilter struct {
+	level   int
+	minSize int
+	types   []string
+}
+
+// NewCompressionFilter CompressionFilter 的构造函数，默认压缩超过 1KB 的文本、JSON、
+// JavaScript、XML 和 SVG 响应
+func NewCompressionFilter() *CompressionFilter {
+	return &CompressionFilter{
+		level:   gzip.DefaultCompression,
+		minSize: 1024,
+		types: []string{
+			"text/*",
+			MIMEApplicationJSON,
+			MIMEApplicationJavaScript,
+			MIMEApplicationXML,
+			MIMEApplicationProblemJSON,
+			MIMEJsonAPI,
+			MIMEJsonStream,
+			"image/svg+xml",
+		},
+	}
+}
+
+// WithLevel 设置压缩级别，取值和 compress/flate 相同
+func (f *CompressionFilter) WithLevel(level int) *CompressionFilter {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		panic("invalid compression level " + strconv.Itoa(level))
+	}
+	f.level = level
+	return f
+}
+
+// WithMinSize 设置压缩的大小阈值，小于该值的响应不压缩
+func (f *CompressionFilter) WithMinSize(size int) *CompressionFilter {
+	f.minSize = size
+	return f
+}
+
+// WithTypes 设置需要压缩的 MIME 类型，支持 text/* 这样的通配符
+func (f *CompressionFilter) WithTypes(types ...string) *CompressionFilter {
+	f.types = types
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *CompressionFilter) Name() string {
+	return "compression"
+}
+
+func (f *CompressionFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	r := ctx.Request()
+
+	if ctx.IsWebSocket() || r.Header.Get("Range") != "" {
+		chain.Next(ctx)
+		return
+	}
+
+	w := ctx.ResponseWriter()
+	w.Header().Add(HeaderVary, HeaderAcceptEncoding)
+
+	encoding := negotiateEncoding(r.Header.Get(HeaderAcceptEncoding))
+	if encoding == "" {
+		chain.Next(ctx)
+		return
+	}
+
+	cw := &compressWriter{ResponseWriter: w, filter: f, encoding: encoding}
+	ctx.SetResponseWriter(cw)
+	defer func() {
+		cw.close()
+		// 外层的过滤器和容器直接写入原来的 ResponseWriter，不能再写入已经关闭的压缩流
+		ctx.SetResponseWriter(w)
+	}()
+	chain.Next(ctx)
+}
+
+// compressible 返回 MIME 类型是否需要压缩
+func (f *CompressionFilter) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range f.types {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding 根据 Accept-Encoding 选择压缩编码，q 值相同时优先 gzip，
+// 都不接受时返回空字符串
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate} {
+		if q := encodingQuality(acceptEncoding, encoding); q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// encodingQuality 返回 Accept-Encoding 对编码的 q 值，明确列出的编码优先于 *
+func encodingQuality(acceptEncoding string, encoding string) float64 {
+	q, matched := 0.0, false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name != encoding && (name != "*" || matched) {
+			continue
+		}
+
+		v := 1.0
+		for _, p := range params[1:] {
+			if p = strings.TrimSpace(p); strings.HasPrefix(p, "q=") {
+				if f, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					v = f
+				}
+			}
+		}
+		q, matched = v, name == encoding
+	}
+	return q
+}
+
+// compressWriter 的状态
+const (
+	compressUndecided = iota // 数据还没有达到阈值，暂存在缓冲区中
+	compressSkip             // 不压缩，直接写入原来的 http.ResponseWriter
+	compressOn               // 压缩后写入原来的 http.ResponseWriter
+)
+
+// compressor gzip.Writer 和 flate.Writer 的公共接口
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter 压缩响应数据的 http.ResponseWriter
+type compressWriter struct {
+	http.ResponseWriter
+
+	filter      *CompressionFilter
+	encoding    string
+	state       int
+	status      int // WriteHeader 设置的状态码，0 表示没有设置
+	wroteHeader bool
+	buf         []byte
+	enc         compressor
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	if w.state != compressUndecided {
+		w.writeHeader(code)
+		return
+	}
+	w.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified ||
+		w.Header().Get(HeaderContentEncoding) != "" {
+		_ = w.skip()
+		w.writeHeader(code)
+	}
+}
+
+// writeHeader 向原来的 http.ResponseWriter 写入响应头
+func (w *compressWriter) writeHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code == 0 {
+			code = http.StatusOK
+		}
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	switch w.state {
+	case compressSkip:
+		w.writeHeader(w.status)
+		return w.ResponseWriter.Write(p)
+	case compressOn:
+		return w.enc.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if !w.compressible() {
+		if err := w.skip(); err != nil {
+			return 0, err
+		}
+	} else if len(w.buf) >= w.filter.minSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// compressible 返回响应是否需要压缩，没有设置 Content-Type 时根据已有的数据推断
+func (w *compressWriter) compressible() bool {
+	h := w.Header()
+	if h.Get(HeaderContentEncoding) != "" {
+		return false
+	}
+	contentType := h.Get(HeaderContentType)
+	if contentType == "" {
+		// 压缩之后 net/http 无法再推断 Content-Type
+		contentType = http.DetectContentType(w.buf)
+		h.Set(HeaderContentType, contentType)
+	}
+	return w.filter.compressible(contentType)
+}
+
+// skip 不压缩，写入缓冲区中的数据
+func (w *compressWriter) skip() error {
+	w.state = compressSkip
+	if len(w.buf) == 0 {
+		return nil
+	}
+	w.writeHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// start 开始压缩，压缩缓冲区中的数据
+func (w *compressWriter) start() (err error) {
+	w.state = compressOn
+
+	h := w.Header()
+	h.Del(HeaderContentLength)
+	h.Set(HeaderContentEncoding, w.encoding)
+	w.writeHeader(w.status)
+
+	if w.encoding == EncodingGzip {
+		w.enc, err = gzip.NewWriterLevel(w.ResponseWriter, w.filter.level)
+	} else {
+		w.enc, err = flate.NewWriter(w.ResponseWriter, w.filter.level)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.enc.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// Flush 输出已经写入的数据，还没有达到阈值时按照 MIME 类型决定是否压缩，
+// 因为流式响应的最终大小是未知的
+func (w *compressWriter) Flush() {
+	if w.state == compressUndecided {
+		if len(w.buf) == 0 && w.status == 0 {
+			return
+		}
+		if w.compressible() {
+			_ = w.start()
+		} else {
+			_ = w.skip()
+		}
+	}
+	if w.state == compressOn {
+		_ = w.enc.Flush()
+	}
+	w.writeHeader(w.status)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("http.Hijacker is not supported")
+}
+
+// close 结束压缩，没有达到阈值的数据不压缩直接输出
+func (w *compressWriter) close() {
+	switch w.state {
+	case compressUndecided:
+		if len(w.buf) > 0 || w.status != 0 {
+			w.writeHeader(w.status)
+		}
+		_ = w.skip()
+	case compressOn:
+		// 写入失败通常是客户端断开了连接，处理函数写入时已经感知到了
+		_ = w.enc.Close()
+	}
+}
@@ -17,12 +17,45 @@
 package SpringWeb
 
 const (
-	HeaderContentDisposition = "Content-Disposition"
-	HeaderContentType        = "Content-Type"
-	HeaderXForwardedProto    = "X-Forwarded-Proto"
-	HeaderXForwardedProtocol = "X-Forwarded-Protocol"
-	HeaderXForwardedSsl      = "X-Forwarded-Ssl"
-	HeaderXUrlScheme         = "X-Url-Scheme"
+	HeaderAccept                        = "Accept"
+	HeaderAcceptEncoding                = "Accept-Encoding"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAllow                         = "Allow"
+	HeaderAuthorization                 = "Authorization"
+	HeaderContentDisposition            = "Content-Disposition"
+	HeaderContentEncoding               = "Content-Encoding"
+	HeaderContentLength                 = "Content-Length"
+	HeaderContentSecurityPolicy         = "Content-Security-Policy"
+	HeaderContentType                   = "Content-Type"
+	HeaderETag                          = "ETag"
+	HeaderForwarded                     = "Forwarded"
+	HeaderOrigin                        = "Origin"
+	HeaderPermissionsPolicy             = "Permissions-Policy"
+	HeaderRateLimitLimit                = "RateLimit-Limit"
+	HeaderRateLimitRemaining            = "RateLimit-Remaining"
+	HeaderRateLimitReset                = "RateLimit-Reset"
+	HeaderReferrerPolicy                = "Referrer-Policy"
+	HeaderRetryAfter                    = "Retry-After"
+	HeaderStrictTransportSecurity       = "Strict-Transport-Security"
+	HeaderVary                          = "Vary"
+	HeaderWWWAuthenticate               = "WWW-Authenticate"
+	HeaderXContentTypeOptions           = "X-Content-Type-Options"
+	HeaderXForwardedFor                 = "X-Forwarded-For"
+	HeaderXForwardedHost                = "X-Forwarded-Host"
+	HeaderXForwardedProto               = "X-Forwarded-Proto"
+	HeaderXForwardedProtocol            = "X-Forwarded-Protocol"
+	HeaderXForwardedSsl                 = "X-Forwarded-Ssl"
+	HeaderXFrameOptions                 = "X-Frame-Options"
+	HeaderXRealIP                       = "X-Real-IP"
+	HeaderXRequestID                    = "X-Request-ID"
+	HeaderXUrlScheme                    = "X-Url-Scheme"
 
 	CharsetUTF8 = "charset=UTF-8"
 
@@ -34,6 +67,7 @@ const (
 	MIMEApplicationXMLCharsetUTF8        = MIMEApplicationXML + "; " + CharsetUTF8
 	MIMETextXML                          = "text/xml"
 	MIMETextXMLCharsetUTF8               = MIMETextXML + "; " + CharsetUTF8
+	MIMEApplicationProblemJSON           = "application/problem+json"
 	MIMEApplicationForm                  = "application/x-www-form-urlencoded"
 	MIMEApplicationProtobuf              = "application/protobuf"
 	MIMEApplicationMsgpack               = "application/msgpack"
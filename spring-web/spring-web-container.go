@@ -19,13 +19,39 @@ package SpringWeb
 import (
 	"context"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
 
+	"github.com/go-spring/go-spring-parent/spring-error"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 // Handler Web 处理函数
 type Handler func(WebContext)
 
+// RecoveryHandler 处理 Web 处理函数或者过滤器中发生的 panic，err 是 panic 的值，
+// stack 是发生 panic 时的调用栈。可以在这里输出自定义的错误响应或者上报错误。
+type RecoveryHandler func(ctx WebContext, err interface{}, stack []byte)
+
+// TrailingSlashPolicy 请求路径尾部斜杠的处理策略
+type TrailingSlashPolicy int
+
+const (
+	TrailingSlashStrict   TrailingSlashPolicy = iota // 严格匹配，不做任何处理
+	TrailingSlashRedirect                            // 重定向到注册的路径
+	TrailingSlashTolerate                            // 直接按照注册的路径处理
+)
+
+// InterruptPolicy 过滤器既没有调用 chain.Next() 也没有写入响应时的处理策略
+type InterruptPolicy int
+
+const (
+	InterruptIgnore InterruptPolicy = iota // 不做任何处理
+	InterruptWarn                          // 打印警告日志
+	InterruptError                         // 打印错误日志并返回 500
+)
+
 // WebContainer Web 容器
 type WebContainer interface {
 	// WebMapping 路由表
@@ -67,12 +93,76 @@ type WebContainer interface {
 	// SetFilters 设置过滤器列表
 	SetFilters(filters ...Filter)
 
+	// AddFilter 注册一个限定路径和方法的过滤器
+	AddFilter(filter Filter) *FilterRegistration
+
+	// GetFilterRegistrations 返回限定路径和方法的过滤器列表
+	GetFilterRegistrations() []*FilterRegistration
+
+	// GetFilterRegistration 返回指定名称的过滤器注册信息
+	GetFilterRegistration(name string) *FilterRegistration
+
 	// EnableSwagger 是否启用 Swagger 功能
 	EnableSwagger() bool
 
 	// SetEnableSwagger 设置是否启用 Swagger 功能
 	SetEnableSwagger(enable bool)
 
+	// GetNotFoundHandler 返回 404 处理函数
+	GetNotFoundHandler() Handler
+
+	// SetNotFoundHandler 设置 404 处理函数
+	SetNotFoundHandler(fn Handler)
+
+	// GetMethodNotAllowedHandler 返回 405 处理函数
+	GetMethodNotAllowedHandler() Handler
+
+	// SetMethodNotAllowedHandler 设置 405 处理函数
+	SetMethodNotAllowedHandler(fn Handler)
+
+	// GetTrailingSlashPolicy 返回尾部斜杠的处理策略
+	GetTrailingSlashPolicy() TrailingSlashPolicy
+
+	// SetTrailingSlashPolicy 设置尾部斜杠的处理策略
+	SetTrailingSlashPolicy(policy TrailingSlashPolicy)
+
+	// CaseInsensitive 返回路由是否忽略大小写
+	CaseInsensitive() bool
+
+	// SetCaseInsensitive 设置路由是否忽略大小写
+	SetCaseInsensitive(enable bool)
+
+	// CleanPath 返回是否清理请求路径中的 .. 和重复的斜杠
+	CleanPath() bool
+
+	// SetCleanPath 设置是否清理请求路径中的 .. 和重复的斜杠
+	SetCleanPath(enable bool)
+
+	// GetInterruptPolicy 返回过滤器中断链条且没有写入响应时的处理策略
+	GetInterruptPolicy() InterruptPolicy
+
+	// SetInterruptPolicy 设置过滤器中断链条且没有写入响应时的处理策略
+	SetInterruptPolicy(policy InterruptPolicy)
+
+	// GetRecoveryHandler 返回 panic 处理函数
+	GetRecoveryHandler() RecoveryHandler
+
+	// SetRecoveryHandler 设置 panic 处理函数
+	SetRecoveryHandler(fn RecoveryHandler)
+
+	// GetErrorRenderer 返回把 error 转换成响应的函数
+	GetErrorRenderer() ErrorRenderer
+
+	// SetErrorRenderer 设置把 error 转换成响应的函数
+	SetErrorRenderer(fn ErrorRenderer)
+
+	// GetTrustedProxies 返回受信任的反向代理
+	GetTrustedProxies() *TrustedProxies
+
+	// SetTrustedProxies 设置受信任的反向代理，ClientIP() 和 Scheme() 只使用这些代理
+	// 转发的请求头，默认不信任任何代理
+	SetTrustedProxies(proxies *TrustedProxies)
+
 	// Start 启动 Web 容器，非阻塞
 	Start()
 
@@ -90,14 +180,34 @@ type BaseWebContainer struct {
 	keyFile   string
 	certFile  string
 	filters   []Filter
-	enableSwg bool // 是否启用 Swagger 功能
+	filterSet []*FilterRegistration // SetFilters 设置的过滤器
+	filterReg []*FilterRegistration // AddFilter 注册的过滤器
+	enableSwg bool                  // 是否启用 Swagger 功能
+
+	notFound         Handler // 404 处理函数
+	methodNotAllowed Handler // 405 处理函数
+
+	trailingSlash   TrailingSlashPolicy // 尾部斜杠的处理策略
+	caseInsensitive bool                // 路由是否忽略大小写
+	cleanPath       bool                // 是否清理请求路径
+
+	interrupt InterruptPolicy // 过滤器中断链条的处理策略
+	recovery  RecoveryHandler // panic 处理函数
+	renderer  ErrorRenderer   // error 处理函数
+	proxies   *TrustedProxies // 受信任的反向代理
 }
 
 // NewBaseWebContainer BaseWebContainer 的构造函数
 func NewBaseWebContainer() *BaseWebContainer {
 	return &BaseWebContainer{
-		WebMapping: NewDefaultWebMapping(),
-		enableSwg:  true,
+		WebMapping:       NewDefaultWebMapping(),
+		enableSwg:        true,
+		notFound:         DefaultNotFoundHandler,
+		methodNotAllowed: DefaultMethodNotAllowedHandler,
+		trailingSlash:    TrailingSlashRedirect,
+		interrupt:        InterruptWarn,
+		recovery:         DefaultRecoveryHandler,
+		renderer:         DefaultErrorRenderer,
 	}
 }
 
@@ -159,6 +269,152 @@ func (c *BaseWebContainer) GetFilters() []Filter {
 // SetFilters 设置过滤器列表
 func (c *BaseWebContainer) SetFilters(filters ...Filter) {
 	c.filters = filters
+	c.filterSet = make([]*FilterRegistration, 0, len(filters))
+	for _, f := range filters {
+		c.filterSet = append(c.filterSet, NewFilterRegistration(f))
+	}
+}
+
+// AddFilter 注册一个限定路径和方法的过滤器
+func (c *BaseWebContainer) AddFilter(filter Filter) *FilterRegistration {
+	r := NewFilterRegistration(filter)
+	c.filterReg = append(c.filterReg, r)
+	return r
+}
+
+// GetFilterRegistrations 返回限定路径和方法的过滤器列表
+func (c *BaseWebContainer) GetFilterRegistrations() []*FilterRegistration {
+	return c.filterReg
+}
+
+// GetFilterRegistration 返回指定名称的过滤器注册信息
+func (c *BaseWebContainer) GetFilterRegistration(name string) *FilterRegistration {
+	for _, r := range c.registrations() {
+		if r.name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// registrations 返回容器级别的全部过滤器，SetFilters 设置的过滤器在前
+func (c *BaseWebContainer) registrations() []*FilterRegistration {
+	result := make([]*FilterRegistration, 0, len(c.filterSet)+len(c.filterReg))
+	result = append(result, c.filterSet...)
+	return append(result, c.filterReg...)
+}
+
+// ResolveFilters 返回 Mapper 的 method 方法实际执行的过滤器列表。容器的过滤器和
+// Mapper 自身的过滤器一起按照执行顺序稳定排序，顺序相同时容器的过滤器在前，同时去掉
+// Mapper 禁用的过滤器。mapper 为 nil 时返回 404 和 405 使用的过滤器，此时只包含不
+// 限定路径和方法的容器过滤器。
+func (c *BaseWebContainer) ResolveFilters(mapper *Mapper, method string) []Filter {
+
+	var registrations []*FilterRegistration
+	for _, r := range c.registrations() {
+		if mapper == nil {
+			if len(r.includes) == 0 && len(r.excludes) == 0 && r.method == MethodAny {
+				registrations = append(registrations, r)
+			}
+		} else if r.Matches(mapper, GetMethodMask(method)) {
+			registrations = append(registrations, r)
+		}
+	}
+
+	if mapper != nil {
+		for _, f := range mapper.Filters() {
+			registrations = append(registrations, NewFilterRegistration(f))
+		}
+	}
+
+	sort.SliceStable(registrations, func(i, j int) bool {
+		return registrations[i].order < registrations[j].order
+	})
+
+	var filters []Filter
+	for _, r := range registrations {
+		if mapper != nil && r.name != "" && containsString(mapper.DisabledFilters(), r.name) {
+			continue
+		}
+		filters = append(filters, r.filter)
+	}
+	return filters
+}
+
+// ChainFilters 返回 Mapper 的 method 方法最终交给适配器执行的过滤器列表，在
+// ResolveFilters 的结果前面加上实现容器级别功能的过滤器。
+// 路由要求的权限在全部过滤器之后、处理函数之前检查。
+func (c *BaseWebContainer) ChainFilters(mapper *Mapper, method string) []Filter {
+	filters := append([]Filter{&containerFilter{c}}, c.ResolveFilters(mapper, method)...)
+	if mapper != nil && len(mapper.Required()) > 0 {
+		filters = append(filters, newAuthorizeFilter(mapper.Required(), filters))
+	}
+	return filters
+}
+
+// containerFilter 实现容器级别功能的过滤器，总是位于过滤器链条的最前面
+type containerFilter struct {
+	c *BaseWebContainer
+}
+
+func (f *containerFilter) Invoke(ctx WebContext, chain *FilterChain) {
+
+	defer func() {
+		if err := recover(); err != nil {
+			// 由 net/http 负责静默地中断连接
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			// SpringError.ERROR.Panic(err).When(...) 抛出的错误交给 ErrorRenderer 处理
+			if result, ok := err.(*SpringError.RpcResult); ok {
+				f.c.renderer(ctx, &RpcResultError{result})
+				return
+			}
+			f.c.recovery(ctx, err, debug.Stack())
+		}
+	}()
+
+	ctx.Set(errorRendererKey, f.c.renderer)
+	ctx.Set(notFoundHandlerKey, f.c.notFound)
+	ctx.Set(trustedProxiesKey, f.c.proxies)
+	chain.Next(ctx)
+
+	// 过滤器既没有调用 chain.Next() 也没有写入响应，客户端将收到空的 200 响应
+	if f.c.interrupt == InterruptIgnore || chain.Invoked() || ctx.Written() {
+		return
+	}
+	if ctx.ResponseStatus() != http.StatusOK {
+		return
+	}
+	if f.c.interrupt == InterruptWarn {
+		ctx.LogWarnf("filter %T interrupted the chain without writing a response", chain.Interrupter())
+	} else {
+		ctx.LogErrorf("filter %T interrupted the chain without writing a response", chain.Interrupter())
+		ctx.NoContent(http.StatusInternalServerError)
+	}
+}
+
+// checkFilterNames 检查容器级别的过滤器名称是否重复
+func (c *BaseWebContainer) checkFilterNames() {
+	names := make(map[string]bool)
+	for _, r := range c.registrations() {
+		if r.name == "" {
+			continue
+		}
+		if names[r.name] {
+			panic("duplicate filter name " + r.name)
+		}
+		names[r.name] = true
+	}
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
 // EnableSwagger 是否启用 Swagger 功能
@@ -171,9 +427,187 @@ func (c *BaseWebContainer) SetEnableSwagger(enable bool) {
 	c.enableSwg = enable
 }
 
+// GetNotFoundHandler 返回 404 处理函数
+func (c *BaseWebContainer) GetNotFoundHandler() Handler {
+	return c.notFound
+}
+
+// SetNotFoundHandler 设置 404 处理函数
+func (c *BaseWebContainer) SetNotFoundHandler(fn Handler) {
+	c.notFound = fn
+}
+
+// GetMethodNotAllowedHandler 返回 405 处理函数
+func (c *BaseWebContainer) GetMethodNotAllowedHandler() Handler {
+	return c.methodNotAllowed
+}
+
+// SetMethodNotAllowedHandler 设置 405 处理函数
+func (c *BaseWebContainer) SetMethodNotAllowedHandler(fn Handler) {
+	c.methodNotAllowed = fn
+}
+
+// GetTrailingSlashPolicy 返回尾部斜杠的处理策略
+func (c *BaseWebContainer) GetTrailingSlashPolicy() TrailingSlashPolicy {
+	return c.trailingSlash
+}
+
+// SetTrailingSlashPolicy 设置尾部斜杠的处理策略
+func (c *BaseWebContainer) SetTrailingSlashPolicy(policy TrailingSlashPolicy) {
+	c.trailingSlash = policy
+}
+
+// CaseInsensitive 返回路由是否忽略大小写
+func (c *BaseWebContainer) CaseInsensitive() bool {
+	return c.caseInsensitive
+}
+
+// SetCaseInsensitive 设置路由是否忽略大小写
+func (c *BaseWebContainer) SetCaseInsensitive(enable bool) {
+	c.caseInsensitive = enable
+}
+
+// CleanPath 返回是否清理请求路径中的 .. 和重复的斜杠
+func (c *BaseWebContainer) CleanPath() bool {
+	return c.cleanPath
+}
+
+// SetCleanPath 设置是否清理请求路径中的 .. 和重复的斜杠
+func (c *BaseWebContainer) SetCleanPath(enable bool) {
+	c.cleanPath = enable
+}
+
+// GetInterruptPolicy 返回过滤器中断链条且没有写入响应时的处理策略
+func (c *BaseWebContainer) GetInterruptPolicy() InterruptPolicy {
+	return c.interrupt
+}
+
+// SetInterruptPolicy 设置过滤器中断链条且没有写入响应时的处理策略
+func (c *BaseWebContainer) SetInterruptPolicy(policy InterruptPolicy) {
+	c.interrupt = policy
+}
+
+// GetRecoveryHandler 返回 panic 处理函数
+func (c *BaseWebContainer) GetRecoveryHandler() RecoveryHandler {
+	return c.recovery
+}
+
+// SetRecoveryHandler 设置 panic 处理函数
+func (c *BaseWebContainer) SetRecoveryHandler(fn RecoveryHandler) {
+	c.recovery = fn
+}
+
+// GetErrorRenderer 返回把 error 转换成响应的函数
+func (c *BaseWebContainer) GetErrorRenderer() ErrorRenderer {
+	return c.renderer
+}
+
+// SetErrorRenderer 设置把 error 转换成响应的函数
+func (c *BaseWebContainer) SetErrorRenderer(fn ErrorRenderer) {
+	c.renderer = fn
+}
+
+// GetTrustedProxies 返回受信任的反向代理
+func (c *BaseWebContainer) GetTrustedProxies() *TrustedProxies {
+	return c.proxies
+}
+
+// SetTrustedProxies 设置受信任的反向代理，ClientIP() 和 Scheme() 只使用这些代理
+// 转发的请求头，默认不信任任何代理
+func (c *BaseWebContainer) SetTrustedProxies(proxies *TrustedProxies) {
+	c.proxies = proxies
+}
+
+// FixPath 按照容器的路径策略修正没有匹配到路由的请求路径，返回修正后的路径以及是否
+// 需要重定向，无法修正时返回空字符串。该函数只在 404 和 405 时调用，不影响正常请求的性能。
+func (c *BaseWebContainer) FixPath(r *http.Request) (string, bool) {
+
+	p := r.URL.Path
+	if c.cleanPath {
+		p = CleanPath(p)
+	}
+
+	candidates := []string{p}
+	if c.trailingSlash != TrailingSlashStrict && p != "/" {
+		if strings.HasSuffix(p, "/") {
+			candidates = append(candidates, strings.TrimRight(p, "/"))
+		} else {
+			candidates = append(candidates, p+"/")
+		}
+	}
+
+	method := GetMethodMask(r.Method)
+	for _, candidate := range candidates {
+		if fixed, ok := c.lookupPath(method, candidate); ok && fixed != r.URL.Path {
+			return fixed, c.trailingSlash == TrailingSlashRedirect
+		}
+	}
+	return "", false
+}
+
+// lookupPath 查找能够匹配请求路径的路由，返回以路由为准的请求路径
+func (c *BaseWebContainer) lookupPath(method uint32, path string) (string, bool) {
+
+	var keys []string
+	for key, mapper := range c.Mappers() {
+		if mapper.Method()&method != 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	mappers := c.Mappers()
+	for _, key := range keys {
+		if p, ok := matchPath(mappers[key].Path(), path, false); ok {
+			return p, true
+		}
+	}
+
+	if c.caseInsensitive {
+		for _, key := range keys {
+			if p, ok := matchPath(mappers[key].Path(), path, true); ok {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AllowedMethods 返回能够匹配请求路径的所有 HTTP 方法
+func (c *BaseWebContainer) AllowedMethods(path string) []string {
+	var method uint32
+	for _, mapper := range c.Mappers() {
+		if MatchPath(mapper.Path(), path) {
+			method |= mapper.Method()
+		}
+	}
+	if method == 0 {
+		return nil
+	}
+	// 没有注册 OPTIONS 方法时由容器自动应答
+	return GetMethod(method | MethodOptions)
+}
+
+// MethodNotAllowed 处理路径匹配但方法不匹配的请求，自动设置 Allow 头并应答 OPTIONS 请求
+func (c *BaseWebContainer) MethodNotAllowed(ctx WebContext) {
+	allowed := c.AllowedMethods(ctx.Request().URL.Path)
+	if len(allowed) == 0 {
+		c.notFound(ctx)
+		return
+	}
+	ctx.Header(HeaderAllow, strings.Join(allowed, ", "))
+	if ctx.Request().Method == http.MethodOptions {
+		ctx.NoContent(http.StatusNoContent)
+		return
+	}
+	c.methodNotAllowed(ctx)
+}
+
 // PreStart 执行 Start 之前的准备工作
 func (c *BaseWebContainer) PreStart() {
 
+	c.checkFilterNames()
+
 	if c.enableSwg {
 
 		// 注册 path 的 Operation
@@ -182,7 +616,16 @@ func (c *BaseWebContainer) PreStart() {
 				if err := op.parseBind(); err != nil {
 					panic(err)
 				}
-				doc.AddPath(mapper.Path(), mapper.Method(), op)
+				var filters []Filter
+				for _, method := range GetMethod(mapper.Method()) {
+					filters = append(filters, c.ResolveFilters(mapper, method)...)
+				}
+				op.parseSecurity(mapper.Required(), filters)
+				path := mapper.Path()
+				if c.trailingSlash != TrailingSlashStrict && path != "/" {
+					path = strings.TrimRight(path, "/")
+				}
+				doc.AddPath(path, mapper.Method(), op)
 			}
 		}
 
@@ -203,13 +646,16 @@ func InvokeHandler(ctx WebContext, fn Handler, filters []Filter) {
 
 	defer func() {
 		if err := recover(); err != nil {
-			ctx.LogErrorf("Handler(%v) error:%v", fn, err)
-			ctx.Status(http.StatusInternalServerError)
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			DefaultRecoveryHandler(ctx, err, debug.Stack())
 		}
 	}()
 
 	if len(filters) > 0 {
-		filters = append(filters, HandlerFilter(fn))
+		// 复制一份，防止多个路由共享同一个底层数组
+		filters = append(filters[:len(filters):len(filters)], HandlerFilter(fn))
 		chain := NewFilterChain(filters)
 		chain.Next(ctx)
 	} else {
@@ -217,6 +663,51 @@ func InvokeHandler(ctx WebContext, fn Handler, filters []Filter) {
 	}
 }
 
+// DefaultRecoveryHandler 默认的 panic 处理函数，打印错误日志和调用栈，响应还没有
+// 写入时返回 Problem 格式的 500
+func DefaultRecoveryHandler(ctx WebContext, err interface{}, stack []byte) {
+	r := ctx.Request()
+	ctx.LogErrorf("%s %s panic: %v\n%s", r.Method, r.URL.Path, err, stack)
+	if !ctx.Written() {
+		ctx.Problem(NewProblem(http.StatusInternalServerError))
+	}
+}
+
+// notFoundHandlerKey 容器的 404 处理函数在 WebContext 中的 key
+const notFoundHandlerKey = "::SpringWeb::NotFoundHandler"
+
+// NotFound 使用容器设置的 404 处理函数应答请求，例如静态文件不存在时，
+// 没有经过容器时使用 DefaultNotFoundHandler
+func NotFound(ctx WebContext) {
+	if fn, ok := ctx.Get(notFoundHandlerKey).(Handler); ok && fn != nil {
+		fn(ctx)
+		return
+	}
+	DefaultNotFoundHandler(ctx)
+}
+
+// DefaultNotFoundHandler 默认的 404 处理函数
+func DefaultNotFoundHandler(ctx WebContext) {
+	ctx.String(http.StatusNotFound, "404 page not found")
+}
+
+// DefaultMethodNotAllowedHandler 默认的 405 处理函数
+func DefaultMethodNotAllowedHandler(ctx WebContext) {
+	ctx.String(http.StatusMethodNotAllowed, "405 method not allowed")
+}
+
+// RedirectPath 将请求重定向到修正后的路径，GET 和 HEAD 请求使用 301，其他请求使用 308
+func RedirectPath(w http.ResponseWriter, r *http.Request, path string) {
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, path, code)
+}
+
 // HTTP Web HTTP 适配函数
 func HTTP(fn http.HandlerFunc) Handler {
 	return func(webCtx WebContext) {
@@ -35,6 +35,9 @@ type WebContext interface {
 	// LoggerContext 日志接口上下文
 	SpringLogger.LoggerContext
 
+	// SetLoggerContext 设置日志接口上下文
+	SetLoggerContext(logCtx SpringLogger.LoggerContext)
+
 	// NativeContext 返回封装的底层上下文对象
 	NativeContext() interface{}
 
@@ -50,19 +53,24 @@ type WebContext interface {
 	// Request returns `*http.Request`.
 	Request() *http.Request
 
+	// SetRequest sets `*http.Request`.
+	SetRequest(r *http.Request)
+
 	// IsTLS returns true if HTTP connection is TLS otherwise false.
 	IsTLS() bool
 
 	// IsWebSocket returns true if HTTP connection is WebSocket otherwise false.
 	IsWebSocket() bool
 
-	// Scheme returns the HTTP protocol scheme, `http` or `https`.
+	// Scheme returns the HTTP protocol scheme, `http` or `https`. Forwarded
+	// and X-Forwarded-Proto headers are only used when the request comes from
+	// one of the container's trusted proxies.
 	Scheme() string
 
-	// ClientIP implements a best effort algorithm to return the real client IP,
-	// it parses X-Real-IP and X-Forwarded-For in order to work properly with
-	// reverse-proxies such us: nginx or haproxy. Use X-Forwarded-For before
-	// X-Real-Ip as nginx uses X-Real-Ip with the proxy's IP.
+	// ClientIP returns the real client IP. When the request comes from one of
+	// the container's trusted proxies, it walks Forwarded (RFC 7239) or
+	// X-Forwarded-For from right to left and returns the first untrusted
+	// address, falling back to X-Real-IP; otherwise it returns the remote address.
 	ClientIP() string
 
 	// Path returns the registered path for the handler.
@@ -129,6 +137,19 @@ type WebContext interface {
 	// ResponseWriter returns `http.ResponseWriter`.
 	ResponseWriter() http.ResponseWriter
 
+	// SetResponseWriter sets the `http.ResponseWriter` used by the following
+	// filters and the handler, usually a wrapper of ResponseWriter().
+	SetResponseWriter(w http.ResponseWriter)
+
+	// ResponseStatus returns the HTTP response status code set so far.
+	ResponseStatus() int
+
+	// ResponseSize returns the number of body bytes written so far.
+	ResponseSize() int
+
+	// Written returns true if the response header was already written.
+	Written() bool
+
 	// Status sets the HTTP response code.
 	Status(code int)
 
@@ -169,6 +190,10 @@ type WebContext interface {
 	// `callback` to construct the JSONP payload.
 	JSONPBlob(code int, callback string, b []byte)
 
+	// Problem sends an RFC 7807 `application/problem+json` response with
+	// the status code of the problem.
+	Problem(p *Problem)
+
 	// XML sends an XML response with status code.
 	XML(code int, i interface{})
 
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSFilter 跨域资源共享过滤器。需要注册为不限定路径和方法的容器过滤器，这样没有
+// 注册 OPTIONS 方法的路径也能应答预检请求。
+type CORSFilter struct {
+	allowOrigins     []string
+	originPatterns   []*regexp.Regexp
+	allowOriginFunc  func(origin string) bool
+	allowMethods     []string
+	allowHeaders     []string // 为空时原样返回预检请求的 Access-Control-Request-Headers
+	allowCredentials bool
+	exposeHeaders    []string
+	maxAge           time.Duration
+}
+
+// NewCORSFilter CORSFilter 的构造函数，默认不允许任何来源
+func NewCORSFilter() *CORSFilter {
+	return &CORSFilter{
+		allowMethods: []string{
+			http.MethodGet, http.MethodHead, http.MethodPut,
+			http.MethodPatch, http.MethodPost, http.MethodDelete,
+		},
+	}
+}
+
+// AllowOrigins 设置允许的来源，"*" 表示允许所有来源，"https://*.example.com"
+// 表示允许 example.com 的所有子域名。"*" 不能和 AllowCredentials(true) 一起使用，
+// 否则任何网站都可以携带用户的凭证发起跨域请求。
+func (f *CORSFilter) AllowOrigins(origins ...string) *CORSFilter {
+	for _, origin := range origins {
+		if origin == "*" && f.allowCredentials {
+			panic("cors: AllowOrigins(\"*\") can't be used with AllowCredentials(true)")
+		}
+		if origin != "*" && strings.Contains(origin, "*") {
+			expr := "^" + strings.Replace(regexp.QuoteMeta(origin), `\*`, `[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)*`, -1) + "$"
+			f.originPatterns = append(f.originPatterns, regexp.MustCompile(expr))
+		} else {
+			f.allowOrigins = append(f.allowOrigins, origin)
+		}
+	}
+	return f
+}
+
+// AllowOriginPatterns 设置允许的来源的正则表达式
+func (f *CORSFilter) AllowOriginPatterns(patterns ...string) *CORSFilter {
+	for _, pattern := range patterns {
+		f.originPatterns = append(f.originPatterns, regexp.MustCompile(pattern))
+	}
+	return f
+}
+
+// AllowOriginFunc 设置判断来源是否允许的函数
+func (f *CORSFilter) AllowOriginFunc(fn func(origin string) bool) *CORSFilter {
+	f.allowOriginFunc = fn
+	return f
+}
+
+// AllowMethods 设置允许的方法
+func (f *CORSFilter) AllowMethods(methods ...string) *CORSFilter {
+	f.allowMethods = methods
+	return f
+}
+
+// AllowHeaders 设置允许的请求头
+func (f *CORSFilter) AllowHeaders(headers ...string) *CORSFilter {
+	f.allowHeaders = headers
+	return f
+}
+
+// AllowCredentials 设置是否允许携带 Cookie 等凭证，不能和 AllowOrigins("*") 一起使用
+func (f *CORSFilter) AllowCredentials(allow bool) *CORSFilter {
+	if allow && containsString(f.allowOrigins, "*") {
+		panic("cors: AllowOrigins(\"*\") can't be used with AllowCredentials(true)")
+	}
+	f.allowCredentials = allow
+	return f
+}
+
+// ExposeHeaders 设置允许客户端读取的响应头
+func (f *CORSFilter) ExposeHeaders(headers ...string) *CORSFilter {
+	f.exposeHeaders = headers
+	return f
+}
+
+// MaxAge 设置预检请求结果的缓存时间
+func (f *CORSFilter) MaxAge(maxAge time.Duration) *CORSFilter {
+	f.maxAge = maxAge
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *CORSFilter) Name() string {
+	return "cors"
+}
+
+func (f *CORSFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	r := ctx.Request()
+	header := ctx.ResponseWriter().Header()
+
+	origin := r.Header.Get(HeaderOrigin)
+	header.Add(HeaderVary, HeaderOrigin)
+	if origin == "" {
+		chain.Next(ctx)
+		return
+	}
+
+	preflight := r.Method == http.MethodOptions && r.Header.Get(HeaderAccessControlRequestMethod) != ""
+
+	allowOrigin, ok := f.allowOrigin(origin)
+	if !ok {
+		if preflight {
+			ctx.NoContent(http.StatusForbidden)
+		} else {
+			chain.Next(ctx)
+		}
+		return
+	}
+
+	header.Set(HeaderAccessControlAllowOrigin, allowOrigin)
+	if f.allowCredentials {
+		header.Set(HeaderAccessControlAllowCredentials, "true")
+	}
+
+	if !preflight {
+		if len(f.exposeHeaders) > 0 {
+			header.Set(HeaderAccessControlExposeHeaders, strings.Join(f.exposeHeaders, ", "))
+		}
+		chain.Next(ctx)
+		return
+	}
+
+	header.Add(HeaderVary, HeaderAccessControlRequestMethod)
+	header.Add(HeaderVary, HeaderAccessControlRequestHeaders)
+	header.Set(HeaderAccessControlAllowMethods, strings.Join(f.allowMethods, ", "))
+
+	if len(f.allowHeaders) > 0 {
+		header.Set(HeaderAccessControlAllowHeaders, strings.Join(f.allowHeaders, ", "))
+	} else if h := r.Header.Get(HeaderAccessControlRequestHeaders); h != "" {
+		header.Set(HeaderAccessControlAllowHeaders, h)
+	}
+
+	if f.maxAge > 0 {
+		header.Set(HeaderAccessControlMaxAge, strconv.Itoa(int(f.maxAge/time.Second)))
+	}
+
+	ctx.NoContent(http.StatusNoContent)
+}
+
+// allowOrigin 返回 Access-Control-Allow-Origin 的值以及来源是否允许
+func (f *CORSFilter) allowOrigin(origin string) (string, bool) {
+	for _, o := range f.allowOrigins {
+		if o == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+	for _, p := range f.originPatterns {
+		if p.MatchString(origin) {
+			return origin, true
+		}
+	}
+	if f.allowOriginFunc != nil && f.allowOriginFunc(origin) {
+		return origin, true
+	}
+	return "", false
+}
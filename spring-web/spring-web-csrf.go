@@ -0,0 +1,182 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CSRFKey 本次请求的 CSRF token 在 WebContext 中的 key，供模板使用
+const CSRFKey = "csrf"
+
+// CSRFMode CSRF 防护的模式
+type CSRFMode int
+
+const (
+	CSRFDoubleSubmitCookie CSRFMode = iota // token 保存在 Cookie 中，请求同时提交 Cookie 和 token
+	CSRFSynchronizerToken                  // token 保存在服务端的 CSRFTokenStore 中
+)
+
+// CSRFTokenStore 同步令牌模式下保存 token 的服务端存储，通常基于 Session 实现
+type CSRFTokenStore interface {
+	// Load 返回当前会话的 token，不存在时返回空字符串
+	Load(ctx WebContext) string
+
+	// Save 保存当前会话的 token
+	Save(ctx WebContext, token string)
+}
+
+// CSRFFilter 跨站请求伪造防护过滤器，GET、HEAD、OPTIONS 和 TRACE 请求不做校验，
+// 校验失败时通过 RenderError 返回 403
+type CSRFFilter struct {
+	mode        CSRFMode
+	store       CSRFTokenStore
+	tokenLookup []string // 格式为 source:name，source 可以是 header、form 和 query
+	cookie      http.Cookie
+}
+
+// NewCSRFFilter CSRFFilter 的构造函数，默认使用双重提交 Cookie 模式
+func NewCSRFFilter() *CSRFFilter {
+	return &CSRFFilter{
+		mode:        CSRFDoubleSubmitCookie,
+		tokenLookup: []string{"header:X-CSRF-Token", "form:_csrf"},
+		cookie: http.Cookie{
+			Name:     "_csrf",
+			Path:     "/",
+			MaxAge:   int((24 * time.Hour).Seconds()),
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+}
+
+// WithStore 使用同步令牌模式，token 保存在 store 中
+func (f *CSRFFilter) WithStore(store CSRFTokenStore) *CSRFFilter {
+	f.mode = CSRFSynchronizerToken
+	f.store = store
+	return f
+}
+
+// WithTokenLookup 设置提取客户端 token 的位置，格式为 source:name，例如
+// "header:X-CSRF-Token"、"form:_csrf"、"query:_csrf"，按照顺序查找
+func (f *CSRFFilter) WithTokenLookup(lookup ...string) *CSRFFilter {
+	for _, s := range lookup {
+		if ss := strings.SplitN(s, ":", 2); len(ss) != 2 ||
+			(ss[0] != "header" && ss[0] != "form" && ss[0] != "query") {
+			panic(fmt.Errorf("invalid csrf token lookup %s", s))
+		}
+	}
+	f.tokenLookup = lookup
+	return f
+}
+
+// WithCookie 设置双重提交 Cookie 模式下 Cookie 的名称、路径、有效期等属性
+func (f *CSRFFilter) WithCookie(cookie http.Cookie) *CSRFFilter {
+	f.cookie = cookie
+	return f
+}
+
+// Mode 返回 CSRF 防护的模式
+func (f *CSRFFilter) Mode() CSRFMode {
+	return f.mode
+}
+
+// Name 返回过滤器的名称
+func (f *CSRFFilter) Name() string {
+	return "csrf"
+}
+
+func (f *CSRFFilter) Invoke(ctx WebContext, chain *FilterChain) {
+
+	token := f.load(ctx)
+	if token == "" {
+		token = newCSRFToken()
+		f.save(ctx, token)
+	}
+	ctx.Set(CSRFKey, token)
+
+	switch ctx.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		chain.Next(ctx)
+		return
+	}
+
+	clientToken := f.extract(ctx)
+	if clientToken == "" || subtle.ConstantTimeCompare([]byte(clientToken), []byte(token)) != 1 {
+		RenderError(ctx, NewProblem(http.StatusForbidden).WithDetail("invalid csrf token"))
+		return
+	}
+
+	chain.Next(ctx)
+}
+
+// load 返回服务端保存的 token
+func (f *CSRFFilter) load(ctx WebContext) string {
+	if f.mode == CSRFSynchronizerToken {
+		return f.store.Load(ctx)
+	}
+	if c, err := ctx.Cookie(f.cookie.Name); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// save 保存新生成的 token
+func (f *CSRFFilter) save(ctx WebContext, token string) {
+	if f.mode == CSRFSynchronizerToken {
+		f.store.Save(ctx, token)
+		return
+	}
+	cookie := f.cookie
+	cookie.Value = token
+	ctx.SetCookie(&cookie)
+}
+
+// extract 返回客户端提交的 token
+func (f *CSRFFilter) extract(ctx WebContext) string {
+	for _, s := range f.tokenLookup {
+		ss := strings.SplitN(s, ":", 2)
+		var token string
+		switch ss[0] {
+		case "header":
+			token = ctx.Request().Header.Get(ss[1])
+		case "form":
+			// 只从请求体中查找，不包括 URL 中的查询参数
+			token = ctx.Request().PostFormValue(ss[1])
+		case "query":
+			token = ctx.QueryParam(ss[1])
+		}
+		if token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// newCSRFToken 生成 CSRF token
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DecompressionFilter 请求体解压过滤器，支持 Content-Encoding 为 gzip 和 deflate
+// 的请求体，解压之后 Bind 和 GetRawData 读取到的是原始数据。请求体在调用后续的过滤器
+// 之前全部解压，解压后超过大小限制时返回 413，不支持的编码返回 415，数据损坏返回 400。
+type DecompressionFilter struct {
+	maxSize int64
+}
+
+// NewDecompressionFilter DecompressionFilter 的构造函数，默认限制解压后的大小为 10MB
+func NewDecompressionFilter() *DecompressionFilter {
+	return &DecompressionFilter{maxSize: 10 << 20}
+}
+
+// WithMaxSize 设置解压后请求体的最大字节数，用于防御压缩炸弹
+func (f *DecompressionFilter) WithMaxSize(size int64) *DecompressionFilter {
+	f.maxSize = size
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *DecompressionFilter) Name() string {
+	return "decompression"
+}
+
+func (f *DecompressionFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	r := ctx.Request()
+
+	var encodings []string
+	for _, s := range strings.Split(r.Header.Get(HeaderContentEncoding), ",") {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" && s != "identity" {
+			encodings = append(encodings, s)
+		}
+	}
+	if len(encodings) == 0 || r.Body == nil {
+		chain.Next(ctx)
+		return
+	}
+
+	for _, encoding := range encodings {
+		if !isDecompressible(encoding) {
+			// RFC 7694 通过 Accept-Encoding 响应头告知客户端支持的编码
+			ctx.Header(HeaderAcceptEncoding, EncodingGzip+", "+EncodingDeflate)
+			RenderError(ctx, NewProblem(http.StatusUnsupportedMediaType).
+				WithDetail("unsupported content encoding "+encoding))
+			return
+		}
+	}
+
+	data, err := f.readLimited(r.Body)
+	r.Body.Close()
+
+	// 按照和编码相反的顺序解压
+	for i := len(encodings) - 1; err == nil && i >= 0; i-- {
+		data, err = f.decompress(encodings[i], data)
+	}
+
+	if err == errBodyTooLarge {
+		RenderError(ctx, NewProblem(http.StatusRequestEntityTooLarge).
+			WithDetail("request body exceeds "+strconv.FormatInt(f.maxSize, 10)+" bytes"))
+		return
+	}
+	if err != nil {
+		RenderError(ctx, NewProblem(http.StatusBadRequest).WithDetail("invalid compressed body: "+err.Error()))
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+	r.Header.Del(HeaderContentEncoding)
+	r.Header.Set(HeaderContentLength, strconv.Itoa(len(data)))
+	chain.Next(ctx)
+}
+
+// errBodyTooLarge 请求体超过大小限制
+var errBodyTooLarge = errors.New("request body too large")
+
+// isDecompressible 返回是否支持解压该编码
+func isDecompressible(encoding string) bool {
+	switch encoding {
+	case EncodingGzip, "x-gzip", EncodingDeflate:
+		return true
+	}
+	return false
+}
+
+// readLimited 读取数据，超过大小限制时返回 errBodyTooLarge
+func (f *DecompressionFilter) readLimited(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, f.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.maxSize {
+		return nil, errBodyTooLarge
+	}
+	return data, nil
+}
+
+// decompress 解压数据，deflate 优先按照 RFC 1950 的 zlib 格式解析，失败时按照
+// 部分客户端使用的原始 deflate 格式解析
+func (f *DecompressionFilter) decompress(encoding string, data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+
+	switch encoding {
+	case EncodingDeflate:
+		if r, err = zlib.NewReader(bytes.NewReader(data)); err != nil {
+			r = flate.NewReader(bytes.NewReader(data))
+		}
+	default:
+		if r, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	defer r.Close()
+	return f.readLimited(r)
+}
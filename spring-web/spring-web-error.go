@@ -0,0 +1,196 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-error"
+)
+
+// errorRendererKey ErrorRenderer 在 WebContext 中的 key
+const errorRendererKey = "::SpringWeb::ErrorRenderer"
+
+// ErrorHandler 返回 error 的 Web 处理函数
+type ErrorHandler func(WebContext) error
+
+// ErrorRenderer 把 Web 处理函数返回的 error 转换成响应
+type ErrorRenderer func(ctx WebContext, err error)
+
+// ERR 返回 error 的 Web 处理函数的适配函数，返回的 error 交给容器的 ErrorRenderer 处理
+func ERR(fn ErrorHandler) Handler {
+	return func(ctx WebContext) {
+		if err := fn(ctx); err != nil {
+			RenderError(ctx, err)
+		}
+	}
+}
+
+// RenderError 使用容器的 ErrorRenderer 把 error 转换成响应，没有设置时使用 DefaultErrorRenderer
+func RenderError(ctx WebContext, err error) {
+	if fn, ok := ctx.Get(errorRendererKey).(ErrorRenderer); ok && fn != nil {
+		fn(ctx, err)
+	} else {
+		DefaultErrorRenderer(ctx, err)
+	}
+}
+
+// HTTPError 带有 HTTP 状态码的 error
+type HTTPError struct {
+	XMLName xml.Name    `json:"-" xml:"error"`
+	Code    int         `json:"code" xml:"code"`
+	Message string      `json:"message" xml:"message"`
+	Details interface{} `json:"details,omitempty" xml:"details,omitempty"`
+}
+
+// NewHTTPError HTTPError 的构造函数，message 为空时使用状态码的默认描述
+func NewHTTPError(code int, message ...string) *HTTPError {
+	e := &HTTPError{Code: code, Message: http.StatusText(code)}
+	if len(message) > 0 {
+		e.Message = message[0]
+	}
+	return e
+}
+
+// WithDetails 设置错误的详细信息
+func (e *HTTPError) WithDetails(details interface{}) *HTTPError {
+	e.Details = details
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+// RpcResultError 把 panic 抛出的 *SpringError.RpcResult 包装成 error
+type RpcResultError struct {
+	*SpringError.RpcResult
+}
+
+func (e *RpcResultError) Error() string {
+	return fmt.Sprintf("code=%d, msg=%s, err=%s", e.Code, e.Msg, e.Err)
+}
+
+// DefaultErrorRenderer 默认的 ErrorRenderer。Problem 使用 application/problem+json 格式，
+// HTTPError 使用自身的状态码，RpcResultError
+// 和 RPC 适配函数一样返回 200 和 JSON 格式的 RpcResult，其他 error 打印错误日志并返回 500，
+// 不向客户端暴露错误信息。HTTPError 根据 Accept 请求头选择 JSON 或者 XML 格式，无法
+// 编码成 XML 时使用 JSON 格式。
+func DefaultErrorRenderer(ctx WebContext, err error) {
+
+	if ctx.Written() {
+		ctx.LogErrorf("response already written, error: %v", err)
+		return
+	}
+
+	r := ctx.Request()
+
+	var e *HTTPError
+	switch v := err.(type) {
+	case *RpcResultError:
+		ctx.JSON(http.StatusOK, v.RpcResult)
+		return
+	case *Problem:
+		if v.Status >= http.StatusInternalServerError {
+			ctx.LogErrorf("%s %s error: %v", r.Method, r.URL.Path, err)
+		}
+		ctx.Problem(v)
+		return
+	case *HTTPError:
+		e = v
+		if e.Code >= http.StatusInternalServerError {
+			ctx.LogErrorf("%s %s error: %v", r.Method, r.URL.Path, err)
+		}
+	default:
+		ctx.LogErrorf("%s %s error: %v", r.Method, r.URL.Path, err)
+		e = NewHTTPError(http.StatusInternalServerError)
+	}
+
+	// 先编码再写入响应，Details 无法编码成 XML 时（例如 map）使用 JSON 格式
+	accept := r.Header.Get(HeaderAccept)
+	if Negotiate(accept, MIMEApplicationJSON, MIMEApplicationXML) == MIMEApplicationXML {
+		if b, err := xml.Marshal(e); err == nil {
+			ctx.Blob(e.Code, MIMEApplicationXMLCharsetUTF8, append([]byte(xml.Header), b...))
+			return
+		}
+	}
+	ctx.JSON(e.Code, e)
+}
+
+// Negotiate 根据 Accept 请求头从 offers 中选择最合适的 MIME 类型，优先级相同时
+// 选择靠前的类型，Accept 为空或者都不接受时返回第一个类型
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	best, bestQ := offers[0], -1.0
+	for _, offer := range offers {
+		q := acceptQuality(accept, offer)
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	if bestQ <= 0 {
+		return offers[0]
+	}
+	return best
+}
+
+// acceptQuality 返回 Accept 请求头对 MIME 类型的 q 值，不接受时返回 0
+func acceptQuality(accept string, offer string) float64 {
+	if accept == "" {
+		return 1
+	}
+
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		// 越具体的类型优先级越高
+		var s int
+		switch {
+		case mediaType == offer:
+			s = 2
+		case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaType, "*")):
+			s = 1
+		case mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			specificity = s
+			q = 1
+			if v, ok := params["q"]; ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+	}
+	return q
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	json, xml := SpringWeb.MIMEApplicationJSON, SpringWeb.MIMEApplicationXML
+
+	assert.Equal(t, SpringWeb.Negotiate("", json, xml), json)
+	assert.Equal(t, SpringWeb.Negotiate("*/*", json, xml), json)
+	assert.Equal(t, SpringWeb.Negotiate("application/xml", json, xml), xml)
+	assert.Equal(t, SpringWeb.Negotiate("text/html", json, xml), json)
+	assert.Equal(t, SpringWeb.Negotiate("application/xml;q=0.9, application/json", json, xml), json)
+	assert.Equal(t, SpringWeb.Negotiate("application/json;q=0.5, application/*", json, xml), xml)
+	assert.Equal(t, SpringWeb.Negotiate("text/html, application/xml;q=0.9, */*;q=0.8", json, xml), xml)
+	assert.Equal(t, SpringWeb.Negotiate("application/json", xml, json), json)
+}
@@ -22,6 +22,22 @@ type Filter interface {
 	Invoke(ctx WebContext, chain *FilterChain)
 }
 
+// OrderedFilter 声明了执行顺序的过滤器，值越小越先执行，没有声明的过滤器顺序为 0
+type OrderedFilter interface {
+	Filter
+
+	// Order 返回过滤器的执行顺序
+	Order() int
+}
+
+// NamedFilter 声明了名称的过滤器，可以通过名称在指定的路由上禁用
+type NamedFilter interface {
+	Filter
+
+	// Name 返回过滤器的名称
+	Name() string
+}
+
 // handlerFilter 包装 Web 处理函数的过滤器
 type handlerFilter struct {
 	fn Handler
@@ -43,6 +59,7 @@ func (h *handlerFilter) Invoke(ctx WebContext, _ *FilterChain) {
 type FilterChain struct {
 	filters []Filter // 过滤器列表
 	next    int      // 下一个等待执行的过滤器的序号
+	invoked bool     // 链条末端的过滤器是否已经执行
 }
 
 // NewFilterChain FilterChain 的构造函数
@@ -59,5 +76,121 @@ func (chain *FilterChain) Next(ctx WebContext) {
 	}
 	f := chain.filters[chain.next]
 	chain.next++
+	if chain.next == len(chain.filters) {
+		chain.invoked = true
+	}
 	f.Invoke(ctx, chain)
 }
+
+// Invoked 返回链条末端的过滤器(通常是 Web 处理函数)是否已经执行，过滤器可以在
+// chain.Next() 返回后据此判断链条是否被后面的过滤器中断，响应的状态码和长度则
+// 通过 ctx.ResponseStatus() 和 ctx.ResponseSize() 获取。
+func (chain *FilterChain) Invoked() bool {
+	return chain.invoked
+}
+
+// Interrupter 返回中断链条的过滤器，即最后一个执行但没有调用 chain.Next() 的过滤器，
+// 链条没有被中断时返回 nil
+func (chain *FilterChain) Interrupter() Filter {
+	if chain.invoked || chain.next == 0 {
+		return nil
+	}
+	return chain.filters[chain.next-1]
+}
+
+// FilterRegistration 过滤器的注册信息，限定过滤器生效的路径和方法。在容器启动时针对
+// 每个 Mapper 计算一次，因此不会带来请求时的匹配开销。
+type FilterRegistration struct {
+	filter   Filter
+	name     string   // 过滤器的名称
+	includes []string // 生效的路径，Ant 风格，为空时对所有路径生效
+	excludes []string // 排除的路径，Ant 风格
+	method   uint32   // 生效的方法
+	order    int      // 执行顺序，值越小越先执行
+}
+
+// NewFilterRegistration FilterRegistration 的构造函数，过滤器实现了 OrderedFilter
+// 或者 NamedFilter 时使用其声明的顺序和名称
+func NewFilterRegistration(filter Filter) *FilterRegistration {
+	r := &FilterRegistration{
+		filter: filter,
+		method: MethodAny,
+	}
+	if f, ok := filter.(OrderedFilter); ok {
+		r.order = f.Order()
+	}
+	if f, ok := filter.(NamedFilter); ok {
+		r.name = f.Name()
+	}
+	return r
+}
+
+// Filter 返回注册的过滤器
+func (r *FilterRegistration) Filter() Filter {
+	return r.filter
+}
+
+// WithName 设置过滤器的名称
+func (r *FilterRegistration) WithName(name string) *FilterRegistration {
+	r.name = name
+	return r
+}
+
+// Name 返回过滤器的名称
+func (r *FilterRegistration) Name() string {
+	return r.name
+}
+
+// Include 添加生效的路径，Ant 风格，例如 /api/**
+func (r *FilterRegistration) Include(patterns ...string) *FilterRegistration {
+	r.includes = append(r.includes, patterns...)
+	return r
+}
+
+// Exclude 添加排除的路径，Ant 风格，例如 /api/login
+func (r *FilterRegistration) Exclude(patterns ...string) *FilterRegistration {
+	r.excludes = append(r.excludes, patterns...)
+	return r
+}
+
+// WithMethod 设置生效的方法，例如 MethodGet|MethodPost
+func (r *FilterRegistration) WithMethod(method uint32) *FilterRegistration {
+	r.method = method
+	return r
+}
+
+// WithOrder 设置执行顺序，值越小越先执行，覆盖过滤器自身声明的顺序
+func (r *FilterRegistration) WithOrder(order int) *FilterRegistration {
+	r.order = order
+	return r
+}
+
+// Order 返回执行顺序
+func (r *FilterRegistration) Order() int {
+	return r.order
+}
+
+// Matches 判断过滤器对 Mapper 的 method 方法是否生效
+func (r *FilterRegistration) Matches(m *Mapper, method uint32) bool {
+
+	if r.method&method == 0 {
+		return false
+	}
+
+	for _, pattern := range r.excludes {
+		if AntMatch(pattern, m.Path()) {
+			return false
+		}
+	}
+
+	if len(r.includes) == 0 {
+		return true
+	}
+
+	for _, pattern := range r.includes {
+		if AntMatch(pattern, m.Path()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// trustedProxiesKey TrustedProxies 在 WebContext 中的 key
+const trustedProxiesKey = "::SpringWeb::TrustedProxies"
+
+// TrustedProxies 受信任的反向代理，只有直接连接来自受信任的代理时才使用 Forwarded、
+// X-Forwarded-For、X-Real-IP 和 X-Forwarded-Proto 等请求头
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// NewTrustedProxies TrustedProxies 的构造函数，支持 CIDR 和单个 IP，格式错误时 panic
+func NewTrustedProxies(cidrs ...string) *TrustedProxies {
+	p := &TrustedProxies{}
+	for _, s := range cidrs {
+		n, err := parseCIDR(s)
+		if err != nil {
+			panic(err)
+		}
+		p.nets = append(p.nets, n)
+	}
+	return p
+}
+
+// parseCIDR 解析 CIDR，单个 IP 转换为只包含自身的网段
+func parseCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: s}
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	return n, err
+}
+
+// Trusted 返回 ip 是否属于受信任的代理
+func (p *TrustedProxies) Trusted(ip string) bool {
+	if p == nil {
+		return false
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range p.nets {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHop 一个代理记录的转发信息
+type forwardedHop struct {
+	For   string
+	Proto string
+}
+
+// client 返回客户端对应的转发信息，从距离最近的一跳开始跳过受信任的代理，
+// 直接连接不是受信任的代理时返回 nil
+func (p *TrustedProxies) client(r *http.Request) *forwardedHop {
+	remote := remoteIP(r.RemoteAddr)
+	if !p.Trusted(remote) {
+		return nil
+	}
+
+	hops := forwardedHops(r.Header)
+	if len(hops) == 0 {
+		if ip := strings.TrimSpace(r.Header.Get(HeaderXRealIP)); net.ParseIP(ip) != nil {
+			return &forwardedHop{For: ip}
+		}
+		return &forwardedHop{For: remote}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := hops[i].For
+		if net.ParseIP(ip) == nil {
+			// 无法识别的地址，例如 unknown 或者混淆的标识符，使用记录它的代理
+			proxy := remote
+			if i < len(hops)-1 {
+				proxy = hops[i+1].For
+			}
+			return &forwardedHop{For: proxy, Proto: hops[i].Proto}
+		}
+		if i == 0 || !p.Trusted(ip) {
+			return &hops[i]
+		}
+	}
+	return nil
+}
+
+// ClientIP 返回客户端的 IP
+func (p *TrustedProxies) ClientIP(r *http.Request) string {
+	if hop := p.client(r); hop != nil {
+		return hop.For
+	}
+	return remoteIP(r.RemoteAddr)
+}
+
+// Scheme 返回客户端使用的协议，http 或者 https
+func (p *TrustedProxies) Scheme(r *http.Request) string {
+
+	if r.TLS != nil {
+		return "https"
+	}
+
+	hop := p.client(r)
+	if hop == nil {
+		return "http"
+	}
+	if hop.Proto != "" {
+		return hop.Proto
+	}
+
+	if scheme := firstValue(r.Header.Get(HeaderXForwardedProto)); scheme != "" {
+		return scheme
+	}
+	if scheme := firstValue(r.Header.Get(HeaderXForwardedProtocol)); scheme != "" {
+		return scheme
+	}
+	if ssl := r.Header.Get(HeaderXForwardedSsl); ssl == "on" {
+		return "https"
+	}
+	if scheme := r.Header.Get(HeaderXUrlScheme); scheme != "" {
+		return scheme
+	}
+	return "http"
+}
+
+// ClientIP 返回客户端的 IP，只信任容器配置的代理转发的请求头，供 WebContext 的实现使用
+func ClientIP(ctx WebContext) string {
+	p, _ := ctx.Get(trustedProxiesKey).(*TrustedProxies)
+	return p.ClientIP(ctx.Request())
+}
+
+// Scheme 返回客户端使用的协议，只信任容器配置的代理转发的请求头，供 WebContext 的实现使用
+func Scheme(ctx WebContext) string {
+	p, _ := ctx.Get(trustedProxiesKey).(*TrustedProxies)
+	return p.Scheme(ctx.Request())
+}
+
+// forwardedHops 返回请求经过的代理记录的转发信息，优先使用 Forwarded 请求头
+func forwardedHops(h http.Header) []forwardedHop {
+	if values := h[HeaderForwarded]; len(values) > 0 {
+		var hops []forwardedHop
+		for _, e := range ParseForwarded(strings.Join(values, ",")) {
+			hops = append(hops, forwardedHop{For: forwardedNode(e["for"]), Proto: e["proto"]})
+		}
+		return hops
+	}
+
+	var hops []forwardedHop
+	for _, v := range h[HeaderXForwardedFor] {
+		for _, ip := range strings.Split(v, ",") {
+			hops = append(hops, forwardedHop{For: strings.TrimSpace(ip)})
+		}
+	}
+	return hops
+}
+
+// ParseForwarded 解析 RFC 7239 定义的 Forwarded 请求头，返回每个转发元素的参数，
+// 参数名转换为小写，带引号的值去掉引号
+func ParseForwarded(header string) []map[string]string {
+	var result []map[string]string
+	e := make(map[string]string)
+
+	for i := 0; i < len(header); {
+		switch c := header[i]; c {
+		case ',':
+			result = append(result, e)
+			e = make(map[string]string)
+			i++
+			continue
+		case ';', ' ', '\t':
+			i++
+			continue
+		}
+
+		// 参数名
+		j := i
+		for j < len(header) && header[j] != '=' && header[j] != ';' && header[j] != ',' {
+			j++
+		}
+		name := strings.ToLower(strings.TrimSpace(header[i:j]))
+		if j >= len(header) || header[j] != '=' {
+			i = j
+			continue
+		}
+
+		// 参数值，可以是 token 或者 quoted-string
+		i = j + 1
+		var value strings.Builder
+		if i < len(header) && header[i] == '"' {
+			for i++; i < len(header) && header[i] != '"'; i++ {
+				if header[i] == '\\' && i+1 < len(header) {
+					i++
+				}
+				value.WriteByte(header[i])
+			}
+			i++
+		} else {
+			for ; i < len(header) && header[i] != ';' && header[i] != ','; i++ {
+				value.WriteByte(header[i])
+			}
+		}
+		if name != "" {
+			e[name] = strings.TrimSpace(value.String())
+		}
+	}
+	return append(result, e)
+}
+
+// forwardedNode 返回 Forwarded 中节点标识符的 IP 部分，去掉方括号和端口
+func forwardedNode(node string) string {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.IndexByte(node, ']'); end > 0 {
+			return node[1:end]
+		}
+		return node
+	}
+	if i := strings.IndexByte(node, ':'); i >= 0 && strings.Count(node, ":") == 1 {
+		return node[:i]
+	}
+	return node
+}
+
+// remoteIP 返回 RemoteAddr 中的 IP
+func remoteIP(addr string) string {
+	if ip, _, err := net.SplitHostPort(addr); err == nil {
+		return ip
+	}
+	return addr
+}
+
+// firstValue 返回逗号分隔的第一个值
+func firstValue(s string) string {
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
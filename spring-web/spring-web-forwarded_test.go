@@ -0,0 +1,55 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestParseForwarded(t *testing.T) {
+
+	assert.Equal(t, SpringWeb.ParseForwarded(`for=192.0.2.60;proto=http;by=203.0.113.43`),
+		[]map[string]string{{"for": "192.0.2.60", "proto": "http", "by": "203.0.113.43"}})
+
+	assert.Equal(t, SpringWeb.ParseForwarded(`For="[2001:db8:cafe::17]:4711", for=192.0.2.43;Proto=https`),
+		[]map[string]string{{"for": "[2001:db8:cafe::17]:4711"}, {"for": "192.0.2.43", "proto": "https"}})
+
+	assert.Equal(t, SpringWeb.ParseForwarded(`for=unknown;host="a\"b,c"`),
+		[]map[string]string{{"for": "unknown", "host": `a"b,c`}})
+
+	assert.Equal(t, SpringWeb.ParseForwarded(`for`), []map[string]string{{}})
+}
+
+func TestTrustedProxies(t *testing.T) {
+	p := SpringWeb.NewTrustedProxies("10.0.0.0/8", "192.168.1.1", "fd00::/8")
+
+	assert.Equal(t, p.Trusted("10.1.2.3"), true)
+	assert.Equal(t, p.Trusted("192.168.1.1"), true)
+	assert.Equal(t, p.Trusted("192.168.1.2"), false)
+	assert.Equal(t, p.Trusted("fd00::1"), true)
+	assert.Equal(t, p.Trusted("2001:db8::1"), false)
+	assert.Equal(t, p.Trusted("unknown"), false)
+
+	var none *SpringWeb.TrustedProxies
+	assert.Equal(t, none.Trusted("10.1.2.3"), false)
+
+	assert.Panic(t, func() { SpringWeb.NewTrustedProxies("10.0.0.0/33") }, "invalid CIDR")
+	assert.Panic(t, func() { SpringWeb.NewTrustedProxies("localhost") }, "invalid IP")
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// ipRules IP 访问控制规则
+type ipRules struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// newIPRules 解析 CIDR 列表，支持 IPv4、IPv6 和单个 IP
+func newIPRules(allow []string, deny []string) (*ipRules, error) {
+	r := &ipRules{}
+	for _, s := range allow {
+		n, err := parseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		r.allow = append(r.allow, n)
+	}
+	for _, s := range deny {
+		n, err := parseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		r.deny = append(r.deny, n)
+	}
+	return r, nil
+}
+
+// permits 返回是否允许 ip 访问，拒绝列表优先，允许列表为空时允许其他全部 IP
+func (r *ipRules) permits(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range r.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(r.allow) == 0 {
+		return true
+	}
+	for _, n := range r.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPFilter IP 访问控制过滤器，使用 ctx.ClientIP() 判断客户端 IP，因此位于反向代理
+// 之后时需要配置容器的 TrustedProxies。拒绝访问时返回 403。
+type IPFilter struct {
+	allow []string
+	deny  []string
+	rules atomic.Value // *ipRules
+}
+
+// NewIPFilter IPFilter 的构造函数
+func NewIPFilter() *IPFilter {
+	f := &IPFilter{}
+	f.rules.Store(&ipRules{})
+	return f
+}
+
+// Allow 添加允许访问的 CIDR，格式错误时 panic
+func (f *IPFilter) Allow(cidrs ...string) *IPFilter {
+	if err := f.Reload(append(f.allow, cidrs...), f.deny); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Deny 添加拒绝访问的 CIDR，格式错误时 panic
+func (f *IPFilter) Deny(cidrs ...string) *IPFilter {
+	if err := f.Reload(f.allow, append(f.deny, cidrs...)); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Reload 在运行时替换全部的访问控制规则，格式错误时返回错误并保留原来的规则。
+// Reload 可以和请求并发执行，但是多次 Reload 之间需要调用方自己同步。
+func (f *IPFilter) Reload(allow []string, deny []string) error {
+	r, err := newIPRules(allow, deny)
+	if err != nil {
+		return err
+	}
+	f.allow = append([]string(nil), allow...)
+	f.deny = append([]string(nil), deny...)
+	f.rules.Store(r)
+	return nil
+}
+
+// Permits 返回是否允许 ip 访问
+func (f *IPFilter) Permits(ip string) bool {
+	return f.rules.Load().(*ipRules).permits(net.ParseIP(ip))
+}
+
+// Name 返回过滤器的名称
+func (f *IPFilter) Name() string {
+	return "ip"
+}
+
+func (f *IPFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	if ip := ctx.ClientIP(); !f.Permits(ip) {
+		ctx.LogWarnf("ip %s is not allowed to access %s", ip, ctx.Request().URL.Path)
+		RenderError(ctx, NewProblem(http.StatusForbidden).WithDetail("access denied"))
+		return
+	}
+	chain.Next(ctx)
+}
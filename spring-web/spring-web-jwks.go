@@ -0,0 +1,212 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// JWTKeySet 校验 JWT 签名使用的密钥集合
+type JWTKeySet interface {
+	// Key 返回 kid 对应的密钥，HS256 使用 []byte，RS256 使用 *rsa.PublicKey，
+	// ES256 使用 *ecdsa.PublicKey
+	Key(kid string) (interface{}, error)
+}
+
+// StaticKeySet 固定的密钥集合
+type StaticKeySet struct {
+	keys map[string]interface{}
+}
+
+// NewStaticKeySet StaticKeySet 的构造函数
+func NewStaticKeySet() *StaticKeySet {
+	return &StaticKeySet{keys: make(map[string]interface{})}
+}
+
+// AddKey 添加一个密钥，kid 为空时匹配没有 kid 的 JWT
+func (s *StaticKeySet) AddKey(kid string, key interface{}) *StaticKeySet {
+	switch key.(type) {
+	case []byte, *rsa.PublicKey, *ecdsa.PublicKey:
+		s.keys[kid] = key
+	default:
+		panic(fmt.Errorf("unsupported jwt key type %T", key))
+	}
+	return s
+}
+
+func (s *StaticKeySet) Key(kid string) (interface{}, error) {
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown jwt key %q", kid)
+}
+
+// JWKSFile 从本地 JWKS 文件加载的密钥集合，文件修改后自动重新加载以支持密钥轮换
+type JWKSFile struct {
+	path     string
+	interval time.Duration // 检查文件是否修改的最小间隔
+
+	mutex     sync.Mutex
+	keys      map[string]interface{}
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewJWKSFile JWKSFile 的构造函数，interval 是检查文件是否修改的间隔，遇到未知的
+// kid 时会立即检查
+func NewJWKSFile(path string, interval time.Duration) (*JWKSFile, error) {
+	s := &JWKSFile{path: path, interval: interval}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *JWKSFile) Key(kid string) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, ok := s.keys[kid]
+	if !ok || time.Since(s.lastCheck) >= s.interval {
+		// 重新加载失败时继续使用原来的密钥
+		_ = s.reload()
+		key, ok = s.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown jwt key %q", kid)
+	}
+	return key, nil
+}
+
+// reload 文件修改后重新加载密钥
+func (s *JWKSFile) reload() error {
+	s.lastCheck = time.Now()
+
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if s.keys != nil && fi.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(b)
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	s.modTime = fi.ModTime()
+	return nil
+}
+
+// jwk RFC 7517 定义的 JSON Web Key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS 解析 JWKS，返回 kid 到密钥的映射，支持 RSA、P-256 的 EC 和 oct 类型的密钥。
+// 按照 RFC 7517 第 5 节忽略不支持或者无效的密钥，没有可用的密钥时返回 error。
+func ParseJWKS(b []byte) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.key(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable key in jwks")
+	}
+	return keys, nil
+}
+
+// key 返回 JWK 对应的密钥
+func (k *jwk) key() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("invalid ec point")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
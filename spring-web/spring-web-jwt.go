@@ -0,0 +1,313 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWTClaimsKey 校验通过的 JWT 声明在 WebContext 中的 key
+const JWTClaimsKey = "::SpringWeb::JWTClaims"
+
+// JWT 签名算法
+const (
+	JWTAlgHS256 = "HS256"
+	JWTAlgRS256 = "RS256"
+	JWTAlgES256 = "ES256"
+)
+
+// JWTClaims JWT 声明
+type JWTClaims map[string]interface{}
+
+// Subject 返回 sub 声明
+func (c JWTClaims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Strings 返回字符串或者字符串数组类型的声明，字符串使用空格分隔，例如 scope
+func (c JWTClaims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var a []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				a = append(a, s)
+			}
+		}
+		return a
+	}
+	return nil
+}
+
+// time 返回时间类型的声明
+func (c JWTClaims) time(name string) (time.Time, bool) {
+	if v, ok := c[name].(float64); ok {
+		return time.Unix(int64(v), 0), true
+	}
+	return time.Time{}, false
+}
+
+// JWTClaimsFrom 返回当前请求校验通过的 JWT 声明
+func JWTClaimsFrom(ctx WebContext) JWTClaims {
+	c, _ := ctx.Get(JWTClaimsKey).(JWTClaims)
+	return c
+}
+
+// JWTFilter JWT 校验过滤器，从 Authorization 请求头或者 Cookie 中获取 Bearer Token，
+// 校验通过后把声明保存到 WebContext 中，并设置 Principal(ctx)。校验失败时返回 401。
+// JWTFilter 同时也是一个 Authenticator，可以和其他认证器一起交给 AuthFilter 使用。
+type JWTFilter struct {
+	keys       JWTKeySet
+	algorithms []string
+	issuer     string
+	audience   string
+	clockSkew  time.Duration
+	cookie     string
+	auth       *AuthFilter
+}
+
+// NewJWTFilter JWTFilter 的构造函数，同时注册 swagger 安全定义
+func NewJWTFilter(keys JWTKeySet) *JWTFilter {
+	f := &JWTFilter{
+		keys:       keys,
+		algorithms: []string{JWTAlgHS256, JWTAlgRS256, JWTAlgES256},
+		clockSkew:  time.Minute,
+	}
+	f.auth = NewAuthFilter(f)
+	return f
+}
+
+// WithAlgorithms 设置允许的签名算法
+func (f *JWTFilter) WithAlgorithms(algorithms ...string) *JWTFilter {
+	f.algorithms = algorithms
+	return f
+}
+
+// WithIssuer 设置要求的 iss 声明
+func (f *JWTFilter) WithIssuer(issuer string) *JWTFilter {
+	f.issuer = issuer
+	return f
+}
+
+// WithAudience 设置要求的 aud 声明
+func (f *JWTFilter) WithAudience(audience string) *JWTFilter {
+	f.audience = audience
+	return f
+}
+
+// WithClockSkew 设置校验 exp 和 nbf 时允许的时钟误差，默认 1 分钟
+func (f *JWTFilter) WithClockSkew(skew time.Duration) *JWTFilter {
+	f.clockSkew = skew
+	return f
+}
+
+// WithCookie 设置 Authorization 请求头不存在时读取 JWT 的 Cookie
+func (f *JWTFilter) WithCookie(name string) *JWTFilter {
+	f.cookie = name
+	return f
+}
+
+// WithSecurity 添加 BearerAuth 以外的 swagger 安全定义，例如签发 JWT 的 oauth2 服务
+func (f *JWTFilter) WithSecurity(names ...string) *JWTFilter {
+	f.auth.WithSecurity(names...)
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *JWTFilter) Name() string {
+	return "jwt"
+}
+
+func (f *JWTFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	f.auth.Invoke(ctx, chain)
+}
+
+// Authenticate 校验请求中的 JWT，声明中的 sub 作为名称，scope 或者 scp 作为授权范围，
+// roles 作为角色
+func (f *JWTFilter) Authenticate(ctx WebContext) (*Authentication, error) {
+	token, ok := BearerToken(ctx.Request())
+	if !ok && f.cookie != "" {
+		if c, err := ctx.Cookie(f.cookie); err == nil {
+			token, ok = c.Value, true
+		}
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	claims, err := f.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Set(JWTClaimsKey, claims)
+
+	scopes := claims.Strings("scope")
+	if scopes == nil {
+		scopes = claims.Strings("scp")
+	}
+	return &Authentication{
+		Name:   claims.Subject(),
+		Scheme: "Bearer",
+		Roles:  claims.Strings("roles"),
+		Scopes: scopes,
+		Claims: claims,
+	}, nil
+}
+
+func (f *JWTFilter) securityNames() []string {
+	return f.auth.securityNames()
+}
+
+func (f *JWTFilter) challenges() []string {
+	return f.auth.challenges()
+}
+
+// AddSecurityDefinition 和 BearerAuthenticator 使用相同的安全定义
+func (f *JWTFilter) AddSecurityDefinition() string {
+	return (&BearerAuthenticator{}).AddSecurityDefinition()
+}
+
+func (f *JWTFilter) Challenge() string {
+	return "Bearer"
+}
+
+// Verify 校验 JWT 的签名和 exp、nbf、iss、aud 声明，返回 JWT 的声明
+func (f *JWTFilter) Verify(token string) (JWTClaims, error) {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed jwt")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if !containsString(f.algorithms, header.Alg) {
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", header.Alg)
+	}
+
+	key, err := f.keys.Key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed jwt signature")
+	}
+	if err = verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims JWTClaims
+	if err = decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err = f.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate 校验 exp、nbf、iss、aud 声明
+func (f *JWTFilter) validate(claims JWTClaims) error {
+	now := time.Now()
+
+	if exp, ok := claims.time("exp"); ok && now.After(exp.Add(f.clockSkew)) {
+		return errors.New("jwt is expired")
+	}
+	if nbf, ok := claims.time("nbf"); ok && now.Add(f.clockSkew).Before(nbf) {
+		return errors.New("jwt is not valid yet")
+	}
+	if f.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != f.issuer {
+			return errors.New("invalid jwt issuer")
+		}
+	}
+	if f.audience != "" && !containsString(claims.Strings("aud"), f.audience) {
+		return errors.New("invalid jwt audience")
+	}
+	return nil
+}
+
+// decodeJWTPart 解码 JWT 的头部或者声明
+func decodeJWTPart(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return errors.New("malformed jwt")
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return errors.New("malformed jwt")
+	}
+	return nil
+}
+
+// verifyJWTSignature 校验 JWT 的签名，密钥类型必须和算法匹配，防止算法混淆攻击
+func verifyJWTSignature(alg string, key interface{}, signingInput string, sig []byte) error {
+	invalid := errors.New("invalid jwt signature")
+	hash := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case JWTAlgHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return invalid
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return invalid
+		}
+	case JWTAlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig) != nil {
+			return invalid
+		}
+	case JWTAlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() || len(sig) != 64 {
+			return invalid
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, hash[:], r, s) {
+			return invalid
+		}
+	default:
+		return invalid
+	}
+	return nil
+}
@@ -26,6 +26,8 @@ type Mapper struct {
 	path    string   // 路径
 	handler Handler  // 处理函数
 	filters []Filter // 过滤器列表
+	disable []string // 禁用的过滤器名称
+	require []string // 要求具有的角色或者授权范围
 	swagger *Operation
 }
 
@@ -70,6 +72,29 @@ func (m *Mapper) SetFilters(filters []Filter) *Mapper {
 	return m
 }
 
+// DisableFilters 在当前路由上禁用指定名称的过滤器
+func (m *Mapper) DisableFilters(names ...string) *Mapper {
+	m.disable = append(m.disable, names...)
+	return m
+}
+
+// DisabledFilters 返回当前路由上禁用的过滤器名称
+func (m *Mapper) DisabledFilters() []string {
+	return m.disable
+}
+
+// Require 要求当前路由的认证结果具有全部指定的角色或者授权范围，没有认证时返回 401，
+// 权限不足时返回 403。同时为 swagger 文档添加已注册安全定义的安全要求。
+func (m *Mapper) Require(authorities ...string) *Mapper {
+	m.require = append(m.require, authorities...)
+	return m
+}
+
+// Required 返回当前路由要求具有的角色或者授权范围
+func (m *Mapper) Required() []string {
+	return m.require
+}
+
 // Swagger 生成并返回 Operation 对象
 func (m *Mapper) Swagger(id string) *Operation {
 	m.swagger = NewOperation(id)
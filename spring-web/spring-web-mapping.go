@@ -16,6 +16,12 @@
 
 package SpringWeb
 
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
 // WebMapping 路由表，Spring-Web 使用的路由规则和 echo 完全相同，并对 gin 做了适配。
 type WebMapping interface {
 	// Mappers 返回映射器列表
@@ -50,6 +56,15 @@ type WebMapping interface {
 
 	// OPTIONS 注册 OPTIONS 方法处理函数
 	OPTIONS(path string, fn Handler, filters ...Filter) *Mapper
+
+	// Static 注册静态文件目录
+	Static(prefix string, root string, filters ...Filter) *Mapper
+
+	// StaticFS 注册静态文件系统，可以传入 *StaticFileSystem 定制默认文件、SPA 等功能
+	StaticFS(prefix string, fs http.FileSystem, filters ...Filter) *Mapper
+
+	// Mount 把 http.Handler 挂载到 prefix 下，转发请求时去掉路径中的 prefix
+	Mount(prefix string, h http.Handler, filters ...Filter) *Mapper
 }
 
 // defaultWebMapping 路由表的默认实现
@@ -121,3 +136,40 @@ func (w *defaultWebMapping) HEAD(path string, fn Handler, filters ...Filter) *Ma
 func (w *defaultWebMapping) OPTIONS(path string, fn Handler, filters ...Filter) *Mapper {
 	return w.Request(MethodOptions, path, fn, filters...)
 }
+
+// Static 注册静态文件目录
+func (w *defaultWebMapping) Static(prefix string, root string, filters ...Filter) *Mapper {
+	return w.StaticFS(prefix, http.Dir(root), filters...)
+}
+
+// StaticFS 注册静态文件系统，可以传入 *StaticFileSystem 定制默认文件、SPA 等功能
+func (w *defaultWebMapping) StaticFS(prefix string, fs http.FileSystem, filters ...Filter) *Mapper {
+	sfs, ok := fs.(*StaticFileSystem)
+	if !ok {
+		sfs = NewStaticFileSystem(fs)
+	}
+	path := strings.TrimRight(prefix, "/") + "/*"
+	return w.Request(MethodGet|MethodHead, path, sfs.Handler(), filters...)
+}
+
+// Mount 把 http.Handler 挂载到 prefix 下，转发请求时去掉路径中的 prefix
+func (w *defaultWebMapping) Mount(prefix string, h http.Handler, filters ...Filter) *Mapper {
+	prefix = strings.TrimRight(prefix, "/")
+	return w.Request(MethodAny, prefix+"/*", func(ctx WebContext) {
+		r := ctx.Request()
+
+		// 和 http.StripPrefix 一样使用浅拷贝，不修改原始请求
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		if strings.HasPrefix(r.URL.RawPath, prefix) {
+			r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+		} else {
+			r2.URL.RawPath = ""
+		}
+
+		h.ServeHTTP(ctx.ResponseWriter(), r2)
+	}, filters...)
+}
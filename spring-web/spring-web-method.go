@@ -34,6 +34,19 @@ const (
 	MethodGetPost = MethodGet | MethodPost
 )
 
+// methodOrder HTTP 方法的固定顺序
+var methodOrder = []uint32{
+	MethodGet,
+	MethodHead,
+	MethodPost,
+	MethodPut,
+	MethodPatch,
+	MethodDelete,
+	MethodConnect,
+	MethodOptions,
+	MethodTrace,
+}
+
 // methods
 var methods = map[uint32]string{
 	MethodGet:     http.MethodGet,
@@ -50,10 +63,20 @@ var methods = map[uint32]string{
 // GetMethod 返回 method 对应的 HTTP 方法
 func GetMethod(method uint32) []string {
 	var r []string
-	for k, v := range methods {
+	for _, k := range methodOrder {
 		if method&k == k {
-			r = append(r, v)
+			r = append(r, methods[k])
 		}
 	}
 	return r
 }
+
+// GetMethodMask 返回 HTTP 方法对应的 method 值
+func GetMethodMask(method string) uint32 {
+	for k, v := range methods {
+		if v == method {
+			return k
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/spec"
+)
+
+// Problem RFC 7807 定义的错误响应
+type Problem struct {
+	Type     string `json:"type,omitempty"`     // 错误类型的 URI，默认 about:blank
+	Title    string `json:"title,omitempty"`    // 错误类型的简短描述
+	Status   int    `json:"status,omitempty"`   // HTTP 状态码
+	Detail   string `json:"detail,omitempty"`   // 本次错误的详细描述
+	Instance string `json:"instance,omitempty"` // 本次错误的 URI
+
+	// Extensions 扩展成员，序列化时和标准成员处于同一层级
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem Problem 的构造函数，Title 默认使用状态码的描述
+func NewProblem(status int) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+}
+
+// WithType 设置错误类型的 URI
+func (p *Problem) WithType(typ string) *Problem {
+	p.Type = typ
+	return p
+}
+
+// WithTitle 设置错误类型的简短描述
+func (p *Problem) WithTitle(title string) *Problem {
+	p.Title = title
+	return p
+}
+
+// WithDetail 设置本次错误的详细描述
+func (p *Problem) WithDetail(detail string) *Problem {
+	p.Detail = detail
+	return p
+}
+
+// WithInstance 设置本次错误的 URI
+func (p *Problem) WithInstance(instance string) *Problem {
+	p.Instance = instance
+	return p
+}
+
+// With 添加一个扩展成员
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("status=%d, title=%s, detail=%s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("status=%d, title=%s", p.Status, p.Title)
+}
+
+// problemMembers 防止 MarshalJSON 和 UnmarshalJSON 递归调用
+type problemMembers Problem
+
+// MarshalJSON 序列化标准成员和扩展成员，同名时使用标准成员
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal((*problemMembers)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON 反序列化标准成员，其他成员保存到扩展成员中
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*problemMembers)(p)); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(m, k)
+	}
+	if len(m) > 0 {
+		p.Extensions = m
+	} else {
+		p.Extensions = nil
+	}
+	return nil
+}
+
+// NewProblemResponse 返回 Problem 格式的 swagger 响应，同时注册 Problem 的定义
+func NewProblemResponse(description string) *spec.Response {
+	doc.BindDefinitions(Problem{})
+	return NewBindResponse(Problem{}, description)
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestProblem_JSON(t *testing.T) {
+
+	p := SpringWeb.NewProblem(403).
+		WithType("https://example.com/probs/out-of-credit").
+		WithDetail("Your current balance is 30, but that costs 50.").
+		WithInstance("/account/12345/msgs/abc").
+		With("balance", 30).
+		With("status", 200)
+
+	b, err := json.Marshal(p)
+	assert.Equal(t, err, nil)
+
+	// 扩展成员不能覆盖标准成员
+	assert.Equal(t, string(b), `{"balance":30,"detail":"Your current balance is 30, but that costs 50.",`+
+		`"instance":"/account/12345/msgs/abc","status":403,"title":"Forbidden",`+
+		`"type":"https://example.com/probs/out-of-credit"}`)
+
+	var q SpringWeb.Problem
+	err = json.Unmarshal(b, &q)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, q.Status, 403)
+	assert.Equal(t, q.Title, "Forbidden")
+	assert.Equal(t, q.Extensions, map[string]interface{}{"balance": float64(30)})
+
+	b, _ = json.Marshal(SpringWeb.NewProblem(404))
+	assert.Equal(t, string(b), `{"type":"about:blank","title":"Not Found","status":404}`)
+}
+
+func TestNewProblemResponse(t *testing.T) {
+	resp := SpringWeb.NewProblemResponse("bad request")
+	assert.Equal(t, resp.Schema.Ref.String(), "#/definitions/Problem")
+
+	def := SpringWeb.Swagger().Definitions["Problem"]
+	assert.Equal(t, len(def.Properties), 5)
+	assert.Equal(t, def.Properties["status"].Type[0], "integer")
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyOptions 反向代理的配置
+type ProxyOptions struct {
+	// Upstreams 其他上游服务的地址，和 target 一起轮询，只使用 scheme 和 host 部分
+	Upstreams []string
+
+	// HealthCheckPath 健康检查的路径，为空时出错的上游服务在 FailTimeout 后直接恢复，
+	// 否则在 FailTimeout 后访问该路径，返回 2xx 时才恢复
+	HealthCheckPath string
+
+	// FailTimeout 上游服务出错后被摘除的时间，默认 10 秒
+	FailTimeout time.Duration
+
+	// PreserveHost 是否把请求的 Host 原样转发给上游服务
+	PreserveHost bool
+
+	// FlushInterval 响应数据的刷新周期，负数表示每次写入后立即刷新
+	FlushInterval time.Duration
+
+	// Transport 访问上游服务使用的 http.RoundTripper，默认 http.DefaultTransport
+	Transport http.RoundTripper
+
+	// ModifyResponse 修改上游服务的响应
+	ModifyResponse func(*http.Response) error
+}
+
+// upstream 上游服务
+type upstream struct {
+	url       *url.URL
+	downUntil int64 // 摘除的截止时间，UnixNano
+	checking  int32 // 是否正在进行健康检查
+}
+
+// proxyRequest 转发请求的上下文
+type proxyRequest struct {
+	upstream *upstream
+	path     string
+	scheme   string
+	host     string
+	trusted  bool // 直接连接的是否是受信任的代理
+}
+
+// proxyRequestKey proxyRequest 在 context.Context 中的 key
+type proxyRequestKey struct{}
+
+// reverseProxy 支持路径重写、负载均衡和健康检查的反向代理
+type reverseProxy struct {
+	options   ProxyOptions
+	path      string // 路径模板，支持 {name}、:name 和 * 占位符
+	rawQuery  string
+	upstreams []*upstream
+	next      uint32
+	proxy     *httputil.ReverseProxy
+	client    *http.Client
+}
+
+// Proxy 返回把请求转发到上游服务的 Web 处理函数，target 的路径部分可以使用 {name}
+// 或者 * 引用当前路由捕获的路径参数，路径为空时转发原始的请求路径。支持 WebSocket。
+func Proxy(target string, options *ProxyOptions) Handler {
+
+	if options == nil {
+		options = &ProxyOptions{}
+	}
+
+	p := &reverseProxy{options: *options}
+
+	if p.options.FailTimeout <= 0 {
+		p.options.FailTimeout = 10 * time.Second
+	}
+
+	for i, s := range append([]string{target}, options.Upstreams...) {
+		u, err := url.Parse(s)
+		if err != nil {
+			panic(err)
+		}
+		if i == 0 {
+			p.path = PathConvert(u.Path)
+			p.rawQuery = u.RawQuery
+		}
+		p.upstreams = append(p.upstreams, &upstream{
+			url: &url.URL{Scheme: u.Scheme, Host: u.Host},
+		})
+	}
+
+	p.proxy = &httputil.ReverseProxy{
+		Director:       p.director,
+		Transport:      p.options.Transport,
+		FlushInterval:  p.options.FlushInterval,
+		ModifyResponse: p.options.ModifyResponse,
+		ErrorHandler:   p.errorHandler,
+	}
+
+	p.client = &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: p.options.Transport,
+	}
+
+	return p.serve
+}
+
+// serve 转发请求
+func (p *reverseProxy) serve(ctx WebContext) {
+	r := ctx.Request()
+
+	pr := &proxyRequest{
+		upstream: p.choose(),
+		path:     p.rewritePath(ctx),
+		scheme:   ctx.Scheme(),
+		host:     r.Host,
+	}
+
+	proxies, _ := ctx.Get(trustedProxiesKey).(*TrustedProxies)
+	pr.trusted = proxies.Trusted(remoteIP(r.RemoteAddr))
+
+	r = r.WithContext(context.WithValue(r.Context(), proxyRequestKey{}, pr))
+	p.proxy.ServeHTTP(ctx.ResponseWriter(), r)
+}
+
+// rewritePath 使用路径参数替换路径模板中的占位符
+func (p *reverseProxy) rewritePath(ctx WebContext) string {
+	if p.path == "" {
+		return ctx.Request().URL.Path
+	}
+	var sb strings.Builder
+	for i := 0; i < len(p.path); i++ {
+		switch c := p.path[i]; c {
+		case '*':
+			sb.WriteString(ctx.PathParam("*"))
+		case ':':
+			start := i + 1
+			for i+1 < len(p.path) && p.path[i+1] != '/' {
+				i++
+			}
+			sb.WriteString(ctx.PathParam(p.path[start : i+1]))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// director 修改转发给上游服务的请求
+func (p *reverseProxy) director(r *http.Request) {
+	pr := r.Context().Value(proxyRequestKey{}).(*proxyRequest)
+
+	r.URL.Scheme = pr.upstream.url.Scheme
+	r.URL.Host = pr.upstream.url.Host
+	r.URL.Path = pr.path
+	r.URL.RawPath = ""
+
+	if p.rawQuery == "" || r.URL.RawQuery == "" {
+		r.URL.RawQuery = p.rawQuery + r.URL.RawQuery
+	} else {
+		r.URL.RawQuery = p.rawQuery + "&" + r.URL.RawQuery
+	}
+
+	if !p.options.PreserveHost {
+		r.Host = pr.upstream.url.Host
+	}
+
+	// 直接连接的不是受信任的代理时丢弃客户端伪造的转发信息，
+	// X-Forwarded-For 由 httputil.ReverseProxy 负责追加
+	if !pr.trusted {
+		r.Header.Del(HeaderForwarded)
+		r.Header.Del(HeaderXForwardedFor)
+		r.Header.Del(HeaderXRealIP)
+	}
+	r.Header.Set(HeaderXForwardedHost, pr.host)
+	r.Header.Set(HeaderXForwardedProto, pr.scheme)
+
+	if _, ok := r.Header["User-Agent"]; !ok {
+		// 防止底层使用默认的 User-Agent
+		r.Header.Set("User-Agent", "")
+	}
+}
+
+// errorHandler 上游服务出错时摘除该服务并返回 502，客户端断开连接导致的错误
+// 不代表上游服务不可用，此时不摘除
+func (p *reverseProxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if r.Context().Err() == nil {
+		pr := r.Context().Value(proxyRequestKey{}).(*proxyRequest)
+		until := time.Now().Add(p.options.FailTimeout).UnixNano()
+		atomic.StoreInt64(&pr.upstream.downUntil, until)
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// choose 轮询选择一个可用的上游服务，全部不可用时仍然按照轮询的结果返回
+func (p *reverseProxy) choose() *upstream {
+	n := uint32(len(p.upstreams))
+	start := atomic.AddUint32(&p.next, 1) - 1
+	for i := uint32(0); i < n; i++ {
+		u := p.upstreams[(start+i)%n]
+		if p.available(u) {
+			return u
+		}
+	}
+	return p.upstreams[start%n]
+}
+
+// available 返回上游服务是否可用，摘除时间到期后按照配置进行健康检查
+func (p *reverseProxy) available(u *upstream) bool {
+	downUntil := atomic.LoadInt64(&u.downUntil)
+	if downUntil == 0 {
+		return true
+	}
+	if time.Now().UnixNano() < downUntil {
+		return false
+	}
+	if p.options.HealthCheckPath == "" {
+		atomic.StoreInt64(&u.downUntil, 0)
+		return true
+	}
+	if atomic.CompareAndSwapInt32(&u.checking, 0, 1) {
+		go p.healthCheck(u)
+	}
+	return false
+}
+
+// healthCheck 对上游服务进行健康检查
+func (p *reverseProxy) healthCheck(u *upstream) {
+	defer atomic.StoreInt32(&u.checking, 0)
+
+	var downUntil int64
+	resp, err := p.client.Get(u.url.String() + p.options.HealthCheckPath)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		downUntil = time.Now().Add(p.options.FailTimeout).UnixNano()
+	}
+	atomic.StoreInt64(&u.downUntil, downUntil)
+}
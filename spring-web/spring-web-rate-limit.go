@@ -0,0 +1,303 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm 限流算法
+type RateLimitAlgorithm int
+
+const (
+	RateLimitTokenBucket   = RateLimitAlgorithm(iota) // 令牌桶，允许一定的突发流量
+	RateLimitSlidingWindow                            // 滑动窗口，按照前后两个窗口的加权计数限流
+)
+
+// RateLimit 限流规则，每个 key 在 Window 时间内最多允许 Limit 个请求
+type RateLimit struct {
+	Limit     int
+	Window    time.Duration
+	Algorithm RateLimitAlgorithm
+}
+
+// RateLimitResult 限流结果
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // 配额完全恢复的时间
+	RetryAfter time.Duration // 请求被拒绝时允许重试的时间
+}
+
+// RateLimitStore 限流状态的存储，外部存储 (例如 Redis) 需要自己实现限流算法
+type RateLimitStore interface {
+	// Allow 消耗 key 的一个配额
+	Allow(key string, limit RateLimit) (*RateLimitResult, error)
+}
+
+// RateLimitKeyFunc 返回限流使用的 key，返回空字符串时不限流
+type RateLimitKeyFunc func(ctx WebContext) string
+
+// RateLimitByClientIP 按照客户端 IP 限流
+func RateLimitByClientIP(ctx WebContext) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// RateLimitByPrincipal 按照认证结果限流，没有认证时按照客户端 IP 限流
+func RateLimitByPrincipal(ctx WebContext) string {
+	if auth := Principal(ctx); auth != nil {
+		return "principal:" + auth.Name
+	}
+	return RateLimitByClientIP(ctx)
+}
+
+// RateLimitByRoute 按照路由限流，所有客户端共享配额
+func RateLimitByRoute(ctx WebContext) string {
+	return "route:" + ctx.Request().Method + " " + ctx.Path()
+}
+
+// RateLimitByHeader 按照请求头限流，例如 API Key，请求头不存在时不限流
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(ctx WebContext) string {
+		if v := ctx.GetHeader(name); v != "" {
+			return "header:" + v
+		}
+		return ""
+	}
+}
+
+// RateLimitFilter 限流过滤器，设置 RateLimit-Limit、RateLimit-Remaining 和
+// RateLimit-Reset 响应头，超出限制时返回 429 和 Retry-After 响应头。存储出错时
+// 打印错误日志并放行请求。
+type RateLimitFilter struct {
+	limit RateLimit
+	key   RateLimitKeyFunc
+	store RateLimitStore
+}
+
+// NewRateLimitFilter RateLimitFilter 的构造函数，默认使用令牌桶算法、按照客户端 IP
+// 限流，状态保存在内存中
+func NewRateLimitFilter(limit int, window time.Duration) *RateLimitFilter {
+	if limit <= 0 || window <= 0 {
+		panic("rate limit and window must be positive")
+	}
+	return &RateLimitFilter{
+		limit: RateLimit{Limit: limit, Window: window},
+		key:   RateLimitByClientIP,
+		store: NewMemoryRateLimitStore(),
+	}
+}
+
+// WithAlgorithm 设置限流算法
+func (f *RateLimitFilter) WithAlgorithm(algorithm RateLimitAlgorithm) *RateLimitFilter {
+	f.limit.Algorithm = algorithm
+	return f
+}
+
+// WithKey 设置限流使用的 key
+func (f *RateLimitFilter) WithKey(fn RateLimitKeyFunc) *RateLimitFilter {
+	f.key = fn
+	return f
+}
+
+// WithStore 设置限流状态的存储
+func (f *RateLimitFilter) WithStore(store RateLimitStore) *RateLimitFilter {
+	f.store = store
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *RateLimitFilter) Name() string {
+	return "rateLimit"
+}
+
+func (f *RateLimitFilter) Invoke(ctx WebContext, chain *FilterChain) {
+
+	key := f.key(ctx)
+	if key == "" {
+		chain.Next(ctx)
+		return
+	}
+
+	r, err := f.store.Allow(key, f.limit)
+	if err != nil {
+		ctx.LogErrorf("rate limit store error: %v", err)
+		chain.Next(ctx)
+		return
+	}
+
+	ctx.Header(HeaderRateLimitLimit, strconv.Itoa(r.Limit))
+	ctx.Header(HeaderRateLimitRemaining, strconv.Itoa(r.Remaining))
+	ctx.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(r.Reset)))
+
+	if !r.Allowed {
+		ctx.Header(HeaderRetryAfter, strconv.Itoa(ceilSeconds(r.RetryAfter)))
+		RenderError(ctx, NewProblem(http.StatusTooManyRequests).WithDetail("rate limit exceeded"))
+		return
+	}
+	chain.Next(ctx)
+}
+
+// ceilSeconds 向上取整到秒
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// rateLimitEntry 一个 key 的限流状态
+type rateLimitEntry struct {
+	tokens float64   // 令牌桶：剩余的令牌数
+	count  int       // 滑动窗口：当前窗口的计数
+	prev   int       // 滑动窗口：上一个窗口的计数
+	start  time.Time // 令牌桶：上次补充令牌的时间；滑动窗口：当前窗口的开始时间
+	expire time.Time // 状态完全恢复的时间，之后可以被清理
+}
+
+// rateLimitShard 内存存储的一个分片
+type rateLimitShard struct {
+	mutex     sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+// MemoryRateLimitStore 分片的内存存储，定期清理已经完全恢复的 key
+type MemoryRateLimitStore struct {
+	shards []*rateLimitShard
+}
+
+// NewMemoryRateLimitStore MemoryRateLimitStore 的构造函数
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	s := &MemoryRateLimitStore{shards: make([]*rateLimitShard, 32)}
+	for i := range s.shards {
+		s.shards[i] = &rateLimitShard{entries: make(map[string]*rateLimitEntry)}
+	}
+	return s
+}
+
+// Len 返回当前保存的 key 的数量
+func (s *MemoryRateLimitStore) Len() int {
+	n := 0
+	for _, shard := range s.shards {
+		shard.mutex.Lock()
+		n += len(shard.entries)
+		shard.mutex.Unlock()
+	}
+	return n
+}
+
+func (s *MemoryRateLimitStore) Allow(key string, limit RateLimit) (*RateLimitResult, error) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := s.shards[h.Sum32()%uint32(len(s.shards))]
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(shard.lastSweep) >= limit.Window {
+		shard.sweep(now)
+	}
+
+	e, ok := shard.entries[key]
+	if !ok {
+		e = &rateLimitEntry{tokens: float64(limit.Limit), start: now}
+		shard.entries[key] = e
+	}
+
+	var r *RateLimitResult
+	if limit.Algorithm == RateLimitSlidingWindow {
+		r = e.slidingWindow(now, limit)
+	} else {
+		r = e.tokenBucket(now, limit)
+	}
+	e.expire = now.Add(r.Reset)
+	return r, nil
+}
+
+// sweep 清理已经完全恢复的 key
+func (shard *rateLimitShard) sweep(now time.Time) {
+	for key, e := range shard.entries {
+		if !now.Before(e.expire) {
+			delete(shard.entries, key)
+		}
+	}
+	shard.lastSweep = now
+}
+
+// tokenBucket 令牌桶算法，桶的容量是 Limit，每个 Window 补充 Limit 个令牌
+func (e *rateLimitEntry) tokenBucket(now time.Time, limit RateLimit) *RateLimitResult {
+	rate := float64(limit.Limit) / float64(limit.Window)
+
+	e.tokens = math.Min(float64(limit.Limit), e.tokens+float64(now.Sub(e.start))*rate)
+	e.start = now
+
+	r := &RateLimitResult{Limit: limit.Limit}
+	if e.tokens >= 1 {
+		e.tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = time.Duration((1 - e.tokens) / rate)
+	}
+	r.Remaining = int(e.tokens)
+	r.Reset = time.Duration((float64(limit.Limit) - e.tokens) / rate)
+	return r
+}
+
+// slidingWindow 滑动窗口算法，使用上一个窗口计数按照重叠比例加权后的值估算当前的请求数
+func (e *rateLimitEntry) slidingWindow(now time.Time, limit RateLimit) *RateLimitResult {
+
+	if elapsed := now.Sub(e.start); elapsed >= 2*limit.Window {
+		e.prev, e.count = 0, 0
+		e.start = now
+	} else if elapsed >= limit.Window {
+		e.prev, e.count = e.count, 0
+		e.start = e.start.Add(limit.Window)
+	}
+
+	elapsed := now.Sub(e.start)
+	weight := 1 - float64(elapsed)/float64(limit.Window)
+	estimate := float64(e.prev)*weight + float64(e.count)
+
+	r := &RateLimitResult{Limit: limit.Limit}
+	if estimate+1 <= float64(limit.Limit) {
+		e.count++
+		estimate++
+		r.Allowed = true
+	} else if e.count+1 <= limit.Limit {
+		// 等待上一个窗口的权重降低到足以容纳一个请求
+		need := (float64(e.prev) - (float64(limit.Limit) - 1 - float64(e.count))) / float64(e.prev)
+		r.RetryAfter = time.Duration(need*float64(limit.Window)) - elapsed
+	} else {
+		r.RetryAfter = limit.Window - elapsed
+	}
+
+	r.Remaining = int(math.Max(0, float64(limit.Limit)-math.Ceil(estimate)))
+	if e.count > 0 {
+		r.Reset = 2*limit.Window - elapsed
+	} else {
+		r.Reset = limit.Window - elapsed
+	}
+	return r
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+)
+
+// requestIDKey 请求 ID 在 context.Context 中的 key
+type requestIDKey struct{}
+
+// RequestIDFromContext 返回 context.Context 中保存的请求 ID
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// RequestID 返回当前请求的 ID，没有使用 RequestIDFilter 时返回请求头中的值
+func RequestID(ctx WebContext) string {
+	if id := RequestIDFromContext(ctx.Request().Context()); id != "" {
+		return id
+	}
+	return ctx.Request().Header.Get(HeaderXRequestID)
+}
+
+// RequestIDFilter 请求 ID 过滤器，读取或者生成请求 ID，在响应头中返回，并保存到请求的
+// context.Context 中，之后 ctx.LogXXX 输出的日志都会带上请求 ID
+type RequestIDFilter struct {
+	header    string
+	generator func() string
+}
+
+// NewRequestIDFilter RequestIDFilter 的构造函数
+func NewRequestIDFilter() *RequestIDFilter {
+	return &RequestIDFilter{
+		header:    HeaderXRequestID,
+		generator: newRequestID,
+	}
+}
+
+// WithHeader 设置请求 ID 使用的请求头和响应头，默认 X-Request-ID
+func (f *RequestIDFilter) WithHeader(header string) *RequestIDFilter {
+	f.header = header
+	return f
+}
+
+// WithGenerator 设置请求 ID 的生成函数，默认生成 32 位的随机十六进制字符串
+func (f *RequestIDFilter) WithGenerator(fn func() string) *RequestIDFilter {
+	f.generator = fn
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *RequestIDFilter) Name() string {
+	return "requestID"
+}
+
+func (f *RequestIDFilter) Invoke(ctx WebContext, chain *FilterChain) {
+	r := ctx.Request()
+
+	id := r.Header.Get(f.header)
+	if !validRequestID(id) {
+		id = f.generator()
+	}
+
+	ctx.Header(f.header, id)
+
+	r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+	ctx.SetRequest(r)
+	ctx.SetLoggerContext(&requestIDLoggerContext{
+		DefaultLoggerContext: SpringLogger.NewDefaultLoggerContext(r.Context()),
+		prefix:               "[" + id + "] ",
+	})
+
+	chain.Next(ctx)
+}
+
+// validRequestID 客户端传入的请求 ID 会写入日志，只接受长度有限的可见 ASCII 字符
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID 生成随机的请求 ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestIDLoggerContext 在日志前面加上请求 ID 的 LoggerContext
+type requestIDLoggerContext struct {
+	*SpringLogger.DefaultLoggerContext
+	prefix string
+}
+
+func (c *requestIDLoggerContext) LogTrace(args ...interface{}) {
+	c.DefaultLoggerContext.LogTrace(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogTracef(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogTrace(c.prefix + fmt.Sprintf(format, args...))
+}
+
+func (c *requestIDLoggerContext) LogDebug(args ...interface{}) {
+	c.DefaultLoggerContext.LogDebug(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogDebugf(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogDebug(c.prefix + fmt.Sprintf(format, args...))
+}
+
+func (c *requestIDLoggerContext) LogInfo(args ...interface{}) {
+	c.DefaultLoggerContext.LogInfo(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogInfof(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogInfo(c.prefix + fmt.Sprintf(format, args...))
+}
+
+func (c *requestIDLoggerContext) LogWarn(args ...interface{}) {
+	c.DefaultLoggerContext.LogWarn(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogWarnf(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogWarn(c.prefix + fmt.Sprintf(format, args...))
+}
+
+func (c *requestIDLoggerContext) LogError(args ...interface{}) {
+	c.DefaultLoggerContext.LogError(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogErrorf(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogError(c.prefix + fmt.Sprintf(format, args...))
+}
+
+func (c *requestIDLoggerContext) LogPanic(args ...interface{}) {
+	c.DefaultLoggerContext.LogPanic(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogPanicf(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogPanic(c.prefix + fmt.Sprintf(format, args...))
+}
+
+func (c *requestIDLoggerContext) LogFatal(args ...interface{}) {
+	c.DefaultLoggerContext.LogFatal(c.prefix + fmt.Sprint(args...))
+}
+
+func (c *requestIDLoggerContext) LogFatalf(format string, args ...interface{}) {
+	c.DefaultLoggerContext.LogFatal(c.prefix + fmt.Sprintf(format, args...))
+}
+
+// Logger 返回的 StdLogger 同样在日志前面加上请求 ID
+func (c *requestIDLoggerContext) Logger(tags ...string) SpringLogger.StdLogger {
+	return &requestIDLogger{StdLogger: c.DefaultLoggerContext.Logger(tags...), prefix: c.prefix}
+}
+
+// requestIDLogger 在日志前面加上请求 ID 的 StdLogger
+type requestIDLogger struct {
+	SpringLogger.StdLogger
+	prefix string
+}
+
+func (l *requestIDLogger) Trace(args ...interface{}) {
+	l.StdLogger.Trace(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Tracef(format string, args ...interface{}) {
+	l.StdLogger.Trace(l.prefix + fmt.Sprintf(format, args...))
+}
+
+func (l *requestIDLogger) Debug(args ...interface{}) {
+	l.StdLogger.Debug(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Debugf(format string, args ...interface{}) {
+	l.StdLogger.Debug(l.prefix + fmt.Sprintf(format, args...))
+}
+
+func (l *requestIDLogger) Info(args ...interface{}) {
+	l.StdLogger.Info(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Infof(format string, args ...interface{}) {
+	l.StdLogger.Info(l.prefix + fmt.Sprintf(format, args...))
+}
+
+func (l *requestIDLogger) Warn(args ...interface{}) {
+	l.StdLogger.Warn(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Warnf(format string, args ...interface{}) {
+	l.StdLogger.Warn(l.prefix + fmt.Sprintf(format, args...))
+}
+
+func (l *requestIDLogger) Error(args ...interface{}) {
+	l.StdLogger.Error(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Errorf(format string, args ...interface{}) {
+	l.StdLogger.Error(l.prefix + fmt.Sprintf(format, args...))
+}
+
+func (l *requestIDLogger) Panic(args ...interface{}) {
+	l.StdLogger.Panic(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Panicf(format string, args ...interface{}) {
+	l.StdLogger.Panic(l.prefix + fmt.Sprintf(format, args...))
+}
+
+func (l *requestIDLogger) Fatal(args ...interface{}) {
+	l.StdLogger.Fatal(l.prefix + fmt.Sprint(args...))
+}
+
+func (l *requestIDLogger) Fatalf(format string, args ...interface{}) {
+	l.StdLogger.Fatal(l.prefix + fmt.Sprintf(format, args...))
+}
@@ -51,10 +51,15 @@ func BIND(fn interface{}) Handler {
 	fnVal := reflect.ValueOf(fn)
 
 	return func(webCtx WebContext) {
+
+		// 绑定失败时返回 Problem 格式的 400
+		inVal := reflect.New(inTyp)
+		if err := webCtx.Bind(inVal.Interface()); err != nil {
+			webCtx.Problem(NewProblem(http.StatusBadRequest).WithDetail(err.Error()))
+			return
+		}
+
 		rpcInvoke(webCtx, func() interface{} {
-			inVal := reflect.New(inTyp)
-			err := webCtx.Bind(inVal.Interface())
-			SpringError.ERROR.Panic(err).When(err != nil)
 			outVal := fnVal.Call([]reflect.Value{inVal.Elem()})
 			return outVal[0].Interface()
 		})
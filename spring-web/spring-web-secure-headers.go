@@ -0,0 +1,156 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CSPNonceKey 本次请求的 CSP nonce 在 WebContext 中的 key
+const CSPNonceKey = "cspNonce"
+
+// CSPNoncePlaceholder CSP 策略中 nonce 的占位符
+const CSPNoncePlaceholder = "{nonce}"
+
+// CSPNonce 返回本次请求的 CSP nonce，供模板中的 <script nonce="..."> 使用
+func CSPNonce(ctx WebContext) string {
+	nonce, _ := ctx.Get(CSPNonceKey).(string)
+	return nonce
+}
+
+// SecureHeadersFilter 设置安全相关响应头的过滤器，值为空时不设置对应的响应头。
+// 可以为不同的路由分组注册不同配置的实例。
+type SecureHeadersFilter struct {
+	hstsMaxAge            time.Duration
+	hstsIncludeSubDomains bool
+	hstsPreload           bool
+	csp                   string // 可以包含 {nonce} 占位符
+	frameOptions          string
+	contentTypeNosniff    bool
+	referrerPolicy        string
+	permissionsPolicy     string
+}
+
+// NewSecureHeadersFilter SecureHeadersFilter 的构造函数
+func NewSecureHeadersFilter() *SecureHeadersFilter {
+	return &SecureHeadersFilter{
+		hstsMaxAge:            365 * 24 * time.Hour,
+		hstsIncludeSubDomains: true,
+		frameOptions:          "DENY",
+		contentTypeNosniff:    true,
+		referrerPolicy:        "strict-origin-when-cross-origin",
+	}
+}
+
+// WithHSTS 设置 Strict-Transport-Security，只对 HTTPS 请求生效，maxAge 为 0 时不设置
+func (f *SecureHeadersFilter) WithHSTS(maxAge time.Duration, includeSubDomains bool, preload bool) *SecureHeadersFilter {
+	f.hstsMaxAge = maxAge
+	f.hstsIncludeSubDomains = includeSubDomains
+	f.hstsPreload = preload
+	return f
+}
+
+// WithCSP 设置 Content-Security-Policy，策略中的 {nonce} 会被替换成每个请求不同的随机值，
+// 例如 "script-src 'self' 'nonce-{nonce}'"
+func (f *SecureHeadersFilter) WithCSP(policy string) *SecureHeadersFilter {
+	f.csp = policy
+	return f
+}
+
+// WithFrameOptions 设置 X-Frame-Options，默认 DENY
+func (f *SecureHeadersFilter) WithFrameOptions(option string) *SecureHeadersFilter {
+	f.frameOptions = option
+	return f
+}
+
+// WithContentTypeNosniff 设置是否返回 X-Content-Type-Options: nosniff，默认返回
+func (f *SecureHeadersFilter) WithContentTypeNosniff(enable bool) *SecureHeadersFilter {
+	f.contentTypeNosniff = enable
+	return f
+}
+
+// WithReferrerPolicy 设置 Referrer-Policy，默认 strict-origin-when-cross-origin
+func (f *SecureHeadersFilter) WithReferrerPolicy(policy string) *SecureHeadersFilter {
+	f.referrerPolicy = policy
+	return f
+}
+
+// WithPermissionsPolicy 设置 Permissions-Policy
+func (f *SecureHeadersFilter) WithPermissionsPolicy(policy string) *SecureHeadersFilter {
+	f.permissionsPolicy = policy
+	return f
+}
+
+// Name 返回过滤器的名称
+func (f *SecureHeadersFilter) Name() string {
+	return "secureHeaders"
+}
+
+func (f *SecureHeadersFilter) Invoke(ctx WebContext, chain *FilterChain) {
+
+	if f.hstsMaxAge > 0 && (ctx.IsTLS() || ctx.Scheme() == "https") {
+		v := fmt.Sprintf("max-age=%d", int64(f.hstsMaxAge/time.Second))
+		if f.hstsIncludeSubDomains {
+			v += "; includeSubDomains"
+		}
+		if f.hstsPreload {
+			v += "; preload"
+		}
+		ctx.Header(HeaderStrictTransportSecurity, v)
+	}
+
+	if f.csp != "" {
+		csp := f.csp
+		if strings.Contains(csp, CSPNoncePlaceholder) {
+			nonce := newCSPNonce()
+			ctx.Set(CSPNonceKey, nonce)
+			csp = strings.Replace(csp, CSPNoncePlaceholder, nonce, -1)
+		}
+		ctx.Header(HeaderContentSecurityPolicy, csp)
+	}
+
+	if f.frameOptions != "" {
+		ctx.Header(HeaderXFrameOptions, f.frameOptions)
+	}
+
+	if f.contentTypeNosniff {
+		ctx.Header(HeaderXContentTypeOptions, "nosniff")
+	}
+
+	if f.referrerPolicy != "" {
+		ctx.Header(HeaderReferrerPolicy, f.referrerPolicy)
+	}
+
+	if f.permissionsPolicy != "" {
+		ctx.Header(HeaderPermissionsPolicy, f.permissionsPolicy)
+	}
+
+	chain.Next(ctx)
+}
+
+// newCSPNonce 生成 CSP nonce
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringWeb
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// StaticFileSystem 在 http.FileSystem 的基础上提供静态文件服务的配置
+type StaticFileSystem struct {
+	http.FileSystem
+
+	indexFiles []string // 目录的默认文件
+	spa        bool     // 文件不存在时是否返回根目录的默认文件
+	listing    bool     // 是否允许列出目录内容
+}
+
+// NewStaticFileSystem StaticFileSystem 的构造函数
+func NewStaticFileSystem(fs http.FileSystem) *StaticFileSystem {
+	return &StaticFileSystem{
+		FileSystem: fs,
+		indexFiles: []string{"index.html"},
+	}
+}
+
+// WithIndexFiles 设置目录的默认文件
+func (fs *StaticFileSystem) WithIndexFiles(files ...string) *StaticFileSystem {
+	fs.indexFiles = files
+	return fs
+}
+
+// EnableSPA 设置文件不存在时是否返回根目录的默认文件，适用于单页应用
+func (fs *StaticFileSystem) EnableSPA(enable bool) *StaticFileSystem {
+	fs.spa = enable
+	return fs
+}
+
+// EnableListing 设置是否允许列出目录内容，默认不允许
+func (fs *StaticFileSystem) EnableListing(enable bool) *StaticFileSystem {
+	fs.listing = enable
+	return fs
+}
+
+// Handler 返回静态文件的 Web 处理函数，需要注册在以 /* 结尾的路由上
+func (fs *StaticFileSystem) Handler() Handler {
+	return fs.serve
+}
+
+// serve 响应静态文件请求
+func (fs *StaticFileSystem) serve(ctx WebContext) {
+	name := path.Clean("/" + ctx.PathParam("*"))
+
+	f, d, err := fs.open(name)
+	if err != nil {
+		if !os.IsNotExist(err) || !fs.spa {
+			NotFound(ctx)
+			return
+		}
+		if f, d = fs.openIndex("/"); f == nil {
+			NotFound(ctx)
+			return
+		}
+	}
+
+	if d.IsDir() {
+
+		// 目录必须以斜杠结尾，否则默认文件中的相对路径会出错
+		if u := ctx.Request().URL; !strings.HasSuffix(u.Path, "/") {
+			f.Close()
+			target := path.Base(u.Path) + "/"
+			if u.RawQuery != "" {
+				target += "?" + u.RawQuery
+			}
+			ctx.Redirect(http.StatusMovedPermanently, target)
+			return
+		}
+
+		if index, indexStat := fs.openIndex(name); index != nil {
+			f.Close()
+			f, d = index, indexStat
+		} else if fs.listing {
+			defer f.Close()
+			fs.dirList(ctx, f)
+			return
+		} else {
+			f.Close()
+			if !fs.spa {
+				NotFound(ctx)
+				return
+			}
+			if f, d = fs.openIndex("/"); f == nil {
+				NotFound(ctx)
+				return
+			}
+		}
+	}
+
+	defer f.Close()
+
+	w := ctx.ResponseWriter()
+	if w.Header().Get(HeaderETag) == "" {
+		w.Header().Set(HeaderETag, fmt.Sprintf(`W/"%x-%x"`, d.ModTime().UnixNano(), d.Size()))
+	}
+
+	// ServeContent 负责处理 Range、If-Modified-Since、If-None-Match 等请求头
+	http.ServeContent(w, ctx.Request(), d.Name(), d.ModTime(), f)
+}
+
+// open 打开文件并返回文件信息
+func (fs *StaticFileSystem) open(name string) (http.File, os.FileInfo, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, d, nil
+}
+
+// openIndex 打开目录的默认文件，不存在时返回 nil
+func (fs *StaticFileSystem) openIndex(dir string) (http.File, os.FileInfo) {
+	for _, index := range fs.indexFiles {
+		f, d, err := fs.open(path.Join(dir, index))
+		if err != nil {
+			continue
+		}
+		if d.IsDir() {
+			f.Close()
+			continue
+		}
+		return f, d
+	}
+	return nil, nil
+}
+
+// dirList 列出目录内容
+func (fs *StaticFileSystem) dirList(ctx WebContext, f http.File) {
+	dirs, err := f.Readdir(-1)
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, "Error reading directory")
+		return
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Name() < dirs[j].Name() })
+
+	var sb strings.Builder
+	sb.WriteString("<pre>\n")
+	for _, d := range dirs {
+		name := d.Name()
+		if d.IsDir() {
+			name += "/"
+		}
+		u := url.URL{Path: name}
+		fmt.Fprintf(&sb, "<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(name))
+	}
+	sb.WriteString("</pre>\n")
+	ctx.HTML(http.StatusOK, sb.String())
+}
@@ -166,7 +166,6 @@ func (s *swagger) AddPath(path string, method uint32, op *Operation,
 	parameters ...spec.Parameter) *swagger {
 
 	path = strings.TrimPrefix(path, doc.BasePath)
-	path = strings.TrimRight(path, "/")
 	pathItem, ok := s.Paths.Paths[path]
 
 	if !ok {
@@ -252,10 +251,17 @@ func (s *swagger) BindDefinitionWithTags(i interface{}, attachFields map[string]
 			propName = jsonTag[0]
 		}
 
+		// 忽略不参与序列化的字段
+		if propName == "-" {
+			continue
+		}
+
 		var propSchema *spec.Schema
 		switch k := f.Type.Kind(); k {
 		case reflect.Bool:
 			propSchema = spec.BoolProperty()
+		case reflect.Int:
+			propSchema = new(spec.Schema).Typed("integer", "")
 		case reflect.Int8:
 			propSchema = spec.Int8Property()
 		case reflect.Int16:
@@ -368,6 +374,13 @@ func (s *swagger) AddApiKeySecurityDefinition(name string, in string) *swagger {
 	return s
 }
 
+// AddBearerSecurityDefinition 添加 Bearer 方式认证，swagger 2.0 不支持 Bearer 认证，
+// 使用 Authorization 请求头的 ApiKey 描述
+func (s *swagger) AddBearerSecurityDefinition() *swagger {
+	s.Swagger.SecurityDefinitions["BearerAuth"] = spec.APIKeyAuth(HeaderAuthorization, "header")
+	return s
+}
+
 // AddOauth2ApplicationSecurityDefinition 添加 OAuth2 Application 方式认证
 func (s *swagger) AddOauth2ApplicationSecurityDefinition(name string, tokenUrl string, scopes map[string]string) *swagger {
 	if name == "" {
@@ -549,6 +562,40 @@ func (o *Operation) parseBind() error {
 	return nil
 }
 
+// parseSecurity 根据路由要求的权限和过滤器链条中认证过滤器使用的安全定义添加安全要求，
+// 已经通过 SecuredWith 声明时不做处理。swagger 2.0 只有 oauth2 支持授权范围，并且只
+// 使用安全定义中声明过的授权范围。
+func (o *Operation) parseSecurity(authorities []string, filters []Filter) {
+	if len(authorities) == 0 || len(o.operation.Security) > 0 {
+		return
+	}
+	var names []string
+	for _, f := range filters {
+		if af, ok := f.(authenticationFilter); ok {
+			for _, name := range af.securityNames() {
+				if !containsString(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		scheme, ok := doc.SecurityDefinitions[name]
+		if !ok {
+			continue
+		}
+		scopes := []string{}
+		if scheme.Type == "oauth2" {
+			for _, a := range authorities {
+				if _, ok := scheme.Scopes[a]; ok {
+					scopes = append(scopes, a)
+				}
+			}
+		}
+		o.SecuredWith(name, scopes...)
+	}
+}
+
 // HeaderParam creates a header parameter, this is always required by default
 func HeaderParam(name string, typ, format string) *spec.Parameter {
 	param := spec.HeaderParam(name)
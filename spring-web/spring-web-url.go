@@ -16,6 +16,11 @@
 
 package SpringWeb
 
+import (
+	pathpkg "path"
+	"strings"
+)
+
 // PathConvert {} 路由风格转换成 : 路由风格
 func PathConvert(path string) string {
 	var start int
@@ -38,3 +43,99 @@ func PathConvert(path string) string {
 	}
 	return (string)(result)
 }
+
+// MatchPath 判断请求路径是否能够匹配 echo 风格(也支持 {} 风格)的路由
+func MatchPath(pattern string, path string) bool {
+	_, ok := matchPath(pattern, path, false)
+	return ok
+}
+
+// matchPath 使用请求路径匹配路由，匹配成功时返回以路由中的静态部分为准的请求路径，
+// foldCase 为 true 时静态部分忽略大小写。
+func matchPath(pattern string, path string, foldCase bool) (string, bool) {
+	pattern = PathConvert(pattern)
+	var result []byte
+	i, j := 0, 0
+	for i < len(pattern) {
+		switch c := pattern[i]; c {
+		case '*':
+			return string(append(result, path[j:]...)), true
+		case ':':
+			for i < len(pattern) && pattern[i] != '/' {
+				i++
+			}
+			start := j
+			for j < len(path) && path[j] != '/' {
+				j++
+			}
+			if j == start {
+				return "", false
+			}
+			result = append(result, path[start:j]...)
+		default:
+			if j >= len(path) {
+				return "", false
+			}
+			if path[j] != c && !(foldCase && toLower(path[j]) == toLower(c)) {
+				return "", false
+			}
+			result = append(result, c)
+			i++
+			j++
+		}
+	}
+	if j != len(path) {
+		return "", false
+	}
+	return string(result), true
+}
+
+func toLower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// CleanPath 清理路径中的 . 和 .. 以及重复的斜杠，保留尾部的斜杠
+func CleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	r := pathpkg.Clean("/" + p)
+	if r != "/" && strings.HasSuffix(p, "/") {
+		r += "/"
+	}
+	return r
+}
+
+// AntMatch 判断路径是否匹配 Ant 风格的模式，? 匹配一个字符，* 匹配一段路径中的任意字符，
+// ** 匹配零段或多段路径，例如 /api/** 匹配 /api 以及 /api 下的所有路径。
+func AntMatch(pattern string, path string) bool {
+	return antMatch(strings.Split(strings.Trim(pattern, "/"), "/"),
+		strings.Split(strings.Trim(path, "/"), "/"))
+}
+
+func antMatch(pattern []string, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if pattern = pattern[1:]; len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(path); i++ {
+				if antMatch(pattern, path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := pathpkg.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
@@ -40,3 +40,75 @@ func TestPathConvert(t *testing.T) {
 		assert.Equal(t, "/:a/:bc/*", actual)
 	})
 }
+
+func TestMatchPath(t *testing.T) {
+
+	data := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/", "/", true},
+		{"/get", "/get", true},
+		{"/get", "/get/", false},
+		{"/get", "/set", false},
+		{"/pets/{id}", "/pets/1", true},
+		{"/pets/:id", "/pets/1", true},
+		{"/pets/:id", "/pets/", false},
+		{"/pets/{id}/photos", "/pets/1/photos", true},
+		{"/pets/{id}/photos", "/pets/1/2/photos", false},
+		{"/wild/*", "/wild/", true},
+		{"/wild/*", "/wild/a/b", true},
+		{"/wild/*", "/wild", false},
+	}
+
+	for _, d := range data {
+		assert.Equal(t, SpringWeb.MatchPath(d.pattern, d.path), d.match, d.pattern+" "+d.path)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+
+	data := map[string]string{
+		"":              "/",
+		"/":             "/",
+		"//get":         "/get",
+		"/a/../get":     "/get",
+		"/a/./b/":       "/a/b/",
+		"/../a//b//":    "/a/b/",
+		"pets":          "/pets",
+		"/pets/1/../2/": "/pets/2/",
+	}
+
+	for p, expect := range data {
+		assert.Equal(t, SpringWeb.CleanPath(p), expect, p)
+	}
+}
+
+func TestAntMatch(t *testing.T) {
+
+	data := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/**", "/", true},
+		{"/**", "/a/b/c", true},
+		{"/api/**", "/api", true},
+		{"/api/**", "/api/pets/{id}", true},
+		{"/api/**", "/apis/pets", false},
+		{"/api/*", "/api/pets", true},
+		{"/api/*", "/api/pets/{id}", false},
+		{"/api/*/photos", "/api/{id}/photos", true},
+		{"/api/**/photos", "/api/pets/{id}/photos", true},
+		{"/api/**/photos", "/api/pets/{id}", false},
+		{"/api/pet?", "/api/pets", true},
+		{"/api/pet?", "/api/pet", false},
+		{"/*.html", "/index.html", true},
+		{"/login", "/login", true},
+	}
+
+	for _, d := range data {
+		assert.Equal(t, SpringWeb.AntMatch(d.pattern, d.path), d.match, d.pattern+" "+d.path)
+	}
+}
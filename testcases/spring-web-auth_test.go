@@ -0,0 +1,197 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthFilter(t *testing.T) {
+
+	basic := SpringWeb.NewBasicAuthenticator("admin", func(username, password string) (*SpringWeb.Authentication, error) {
+		if username == "jim" && password == "123" {
+			return &SpringWeb.Authentication{Name: "jim", Roles: []string{"admin"}}, nil
+		}
+		return nil, nil
+	})
+
+	apiKey := SpringWeb.NewAPIKeyAuthenticator("X-API-Key", "header", func(key string) (*SpringWeb.Authentication, error) {
+		if key == "k1" {
+			return &SpringWeb.Authentication{Name: "service"}, nil
+		}
+		return nil, errors.New("unknown api key")
+	})
+
+	cookie := SpringWeb.NewAPIKeyAuthenticator("token", "cookie", func(key string) (*SpringWeb.Authentication, error) {
+		return &SpringWeb.Authentication{Name: "cookie:" + key}, nil
+	})
+
+	bearer := SpringWeb.NewBearerAuthenticator(func(token string) (*SpringWeb.Authentication, error) {
+		return &SpringWeb.Authentication{Name: "bearer", Scheme: "JWT", Scopes: []string{"read"}}, nil
+	})
+
+	assert.Panics(t, func() { SpringWeb.NewAPIKeyAuthenticator("key", "body", nil) })
+
+	f := SpringWeb.NewAuthFilter(basic, apiKey, cookie, bearer)
+
+	// 文档和实际的认证方式一致
+	definitions := SpringWeb.Swagger().SecurityDefinitions
+	assert.Equal(t, "basic", definitions["BasicAuth"].Type)
+	assert.Equal(t, "header", definitions["X-API-Key"].In)
+	assert.Equal(t, "Authorization", definitions["BearerAuth"].Name)
+	assert.Nil(t, definitions["token"])
+
+	var principal *SpringWeb.Authentication
+	handler := func(ctx SpringWeb.WebContext) {
+		principal = SpringWeb.Principal(ctx)
+		ctx.NoContent(http.StatusOK)
+	}
+
+	serve := func(fn func(r *http.Request)) *httptest.ResponseRecorder {
+		principal = nil
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		fn(req)
+		return serveEcho(req, handler, f)
+	}
+
+	rec := serve(func(r *http.Request) { r.SetBasicAuth("jim", "123") })
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "jim", principal.Name)
+	assert.Equal(t, "Basic", principal.Scheme)
+	assert.True(t, principal.HasRole("admin"))
+
+	rec = serve(func(r *http.Request) { r.SetBasicAuth("jim", "456") })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, []string{`Basic realm="admin"`, "Bearer"}, rec.Header()[http.CanonicalHeaderKey(SpringWeb.HeaderWWWAuthenticate)])
+	assert.Nil(t, principal)
+
+	rec = serve(func(r *http.Request) { r.Header.Set("X-API-Key", "k1") })
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ApiKey", principal.Scheme)
+
+	rec = serve(func(r *http.Request) { r.Header.Set("X-API-Key", "k2") })
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown api key")
+
+	rec = serve(func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "token", Value: "c1"}) })
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "cookie:c1", principal.Name)
+
+	rec = serve(func(r *http.Request) { r.Header.Set("Authorization", "bearer abc") })
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "JWT", principal.Scheme)
+	assert.True(t, principal.HasScope("read"))
+
+	// 没有凭证
+	rec = serve(func(r *http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	f.Optional(true)
+	rec = serve(func(r *http.Request) {})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, principal)
+}
+
+// headerAuthenticator 使用请求头模拟认证结果
+type headerAuthenticator struct{}
+
+func (a *headerAuthenticator) Authenticate(ctx SpringWeb.WebContext) (*SpringWeb.Authentication, error) {
+	name := ctx.GetHeader("X-User")
+	if name == "" {
+		return nil, nil
+	}
+	return &SpringWeb.Authentication{
+		Name:   name,
+		Roles:  ctx.Request().Header["X-Role"],
+		Scopes: ctx.Request().Header["X-Scope"],
+	}, nil
+}
+
+func (a *headerAuthenticator) Challenge() string {
+	return `Header realm="test"`
+}
+
+func TestMapper_Require(t *testing.T) {
+
+	// 测试会修改全局的 swagger 对象，结束后恢复
+	swg := SpringWeb.Swagger()
+	definitions, paths := swg.SecurityDefinitions, swg.Paths
+	swg.SecurityDefinitions = spec.SecurityDefinitions{}
+	swg.Paths = &spec.Paths{Paths: make(map[string]spec.PathItem)}
+	defer func() { swg.SecurityDefinitions, swg.Paths = definitions, paths }()
+
+	// 没有被认证过滤器使用的安全定义不会出现在安全要求中
+	swg.AddBasicSecurityDefinition()
+	swg.AddOauth2ImplicitSecurityDefinition("petstore_auth",
+		"https://petstore.swagger.io/oauth/authorize", map[string]string{"write:pets": ""})
+
+	apiKey := SpringWeb.NewAPIKeyAuthenticator("api_key", "header", func(key string) (*SpringWeb.Authentication, error) {
+		return nil, errors.New("unknown api key")
+	})
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.SetEnableSwagger(true)
+	c.AddFilter(SpringWeb.NewAuthFilter(&headerAuthenticator{}, apiKey).
+		WithSecurity("petstore_auth").Optional(true))
+
+	admin := c.GET("/admin", nil).Require("admin", "write:pets")
+	admin.Swagger("admin")
+	public := c.GET("/public", nil)
+
+	serve := func(mapper *SpringWeb.Mapper, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, mapper.Path(), nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return serveEcho(req, func(ctx SpringWeb.WebContext) {
+			ctx.NoContent(http.StatusOK)
+		}, c.ChainFilters(mapper, http.MethodGet)...)
+	}
+
+	rec := serve(public, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(admin, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get("Content-Type"))
+	assert.Equal(t, `Header realm="test"`, rec.Header().Get(SpringWeb.HeaderWWWAuthenticate))
+
+	rec = serve(admin, http.Header{"X-User": {"jim"}, "X-Role": {"admin"}})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "write:pets")
+
+	// 角色和授权范围都可以满足要求
+	rec = serve(admin, http.Header{"X-User": {"jim"}, "X-Role": {"admin"}, "X-Scope": {"write:pets"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	c.PreStart()
+
+	// admin 是角色，没有在 oauth2 安全定义中声明，不作为授权范围
+	security := swg.Paths.Paths["/admin"].Get.Security
+	assert.Equal(t, []map[string][]string{
+		{"api_key": {}},
+		{"petstore_auth": {"write:pets"}},
+	}, security)
+	assert.Nil(t, swg.Paths.Paths["/public"].Get)
+}
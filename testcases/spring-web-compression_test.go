@@ -0,0 +1,217 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/go-spring/go-spring-web/testcases"
+	"github.com/stretchr/testify/assert"
+)
+
+// decompress 按照 Content-Encoding 解压响应
+func decompress(t *testing.T, rec *httptest.ResponseRecorder) string {
+	var r io.Reader = rec.Body
+	switch rec.Header().Get("Content-Encoding") {
+	case "gzip":
+		zr, err := gzip.NewReader(r)
+		assert.NoError(t, err)
+		r = zr
+	case "deflate":
+		r = flate.NewReader(r)
+	}
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestCompressionFilter(t *testing.T) {
+
+	f := SpringWeb.NewCompressionFilter().WithMinSize(64)
+	large := `{"data":"` + strings.Repeat("a", 1000) + `"}`
+
+	for _, engine := range []struct {
+		name  string
+		serve func(*http.Request, SpringWeb.Handler, ...SpringWeb.Filter) *httptest.ResponseRecorder
+	}{
+		{"gin", serveGin},
+		{"echo", serveEcho},
+	} {
+		t.Run(engine.name, func(t *testing.T) {
+
+			serve := func(acceptEncoding string, fn SpringWeb.Handler) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				if acceptEncoding != "" {
+					req.Header.Set("Accept-Encoding", acceptEncoding)
+				}
+				return engine.serve(req, fn, f)
+			}
+
+			blob := func(contentType string, body string) SpringWeb.Handler {
+				return func(ctx SpringWeb.WebContext) {
+					ctx.Blob(http.StatusOK, contentType, []byte(body))
+				}
+			}
+
+			rec := serve("gzip, deflate", blob(SpringWeb.MIMEApplicationJSON, large))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+			assert.Empty(t, rec.Header().Get("Content-Length"))
+			assert.True(t, rec.Body.Len() < len(large))
+			assert.Equal(t, large, decompress(t, rec))
+
+			// brotli 回退到 deflate
+			rec = serve("br, deflate;q=0.5, gzip;q=0.1", blob(SpringWeb.MIMETextPlain, large))
+			assert.Equal(t, "deflate", rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, large, decompress(t, rec))
+
+			rec = serve("gzip;q=0, *", blob(SpringWeb.MIMETextPlain, large))
+			assert.Equal(t, "deflate", rec.Header().Get("Content-Encoding"))
+
+			for _, acceptEncoding := range []string{"", "br", "identity", "*;q=0"} {
+				rec = serve(acceptEncoding, blob(SpringWeb.MIMEApplicationJSON, large))
+				assert.Empty(t, rec.Header().Get("Content-Encoding"), acceptEncoding)
+				assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+				assert.Equal(t, large, rec.Body.String())
+			}
+
+			// 小于阈值
+			rec = serve("gzip", blob(SpringWeb.MIMEApplicationJSON, `{"a":1}`))
+			assert.Empty(t, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, `{"a":1}`, rec.Body.String())
+
+			// 已经压缩过的内容
+			png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 1000)
+			rec = serve("gzip", blob(SpringWeb.MIMEImagePng, png))
+			assert.Empty(t, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, png, rec.Body.String())
+
+			rec = serve("gzip", func(ctx SpringWeb.WebContext) {
+				ctx.Header("Content-Encoding", "br")
+				ctx.Blob(http.StatusOK, SpringWeb.MIMEApplicationJSON, []byte(large))
+			})
+			assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, large, rec.Body.String())
+
+			// 没有设置 Content-Type 时根据内容推断
+			rec = serve("gzip", func(ctx SpringWeb.WebContext) {
+				_, _ = ctx.ResponseWriter().Write([]byte("<html>" + large + "</html>"))
+			})
+			assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+
+			rec = serve("gzip", func(ctx SpringWeb.WebContext) {
+				ctx.NoContent(http.StatusNoContent)
+			})
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+			// 压缩结束之后外层过滤器写入原来的 ResponseWriter
+			var tailErr error
+			outer := &testcases.FuncFilter{Fn: func(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+				chain.Next(ctx)
+				_, tailErr = ctx.ResponseWriter().Write([]byte("tail"))
+			}}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec = engine.serve(req, blob(SpringWeb.MIMEApplicationJSON, large), outer, f)
+			assert.NoError(t, tailErr)
+			assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			assert.True(t, strings.HasSuffix(rec.Body.String(), "tail"))
+
+			// 流式响应在 Flush 之后可以立即解压出已经写入的数据
+			stream := func(fn func(ctx SpringWeb.WebContext) string) {
+				tee := &teeWriter{}
+				capture := &testcases.FuncFilter{Fn: func(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+					tee.ResponseWriter = ctx.ResponseWriter()
+					ctx.SetResponseWriter(tee)
+					chain.Next(ctx)
+				}}
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Accept-Encoding", "gzip")
+				rec := engine.serve(req, func(ctx SpringWeb.WebContext) {
+					expect := fn(ctx)
+					ctx.ResponseWriter().(http.Flusher).Flush()
+
+					zr, err := gzip.NewReader(bytes.NewReader(tee.body.Bytes()))
+					assert.NoError(t, err)
+					b := make([]byte, len(expect))
+					_, err = io.ReadFull(zr, b)
+					assert.NoError(t, err)
+					assert.Equal(t, expect, string(b))
+				}, capture, f)
+				assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			}
+
+			stream(func(ctx SpringWeb.WebContext) string {
+				ctx.Stream(http.StatusOK, SpringWeb.MIMEJsonStream, strings.NewReader("{\"n\":1}\n"))
+				return "{\"n\":1}\n"
+			})
+
+			if engine.name == "gin" {
+				stream(func(ctx SpringWeb.WebContext) string {
+					ctx.SSEvent("message", "hello")
+					return "event:message\ndata:hello\n\n"
+				})
+			}
+		})
+	}
+}
+
+// teeWriter 记录已经输出的响应体
+type teeWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func TestCompressionFilter_Recovery(t *testing.T) {
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.AddFilter(SpringWeb.NewCompressionFilter().WithMinSize(0))
+	filters := c.ChainFilters(c.GET("/", nil), http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := serveEcho(req, func(ctx SpringWeb.WebContext) {
+		panic("boom")
+	}, filters...)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Contains(t, rec.Body.String(), `"status":500`)
+
+	assert.Panics(t, func() { SpringWeb.NewCompressionFilter().WithLevel(10) })
+}
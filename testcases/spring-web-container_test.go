@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -267,3 +268,103 @@ func TestHttpServer(t *testing.T) {
 	_ = server.Shutdown(context.Background())
 	time.Sleep(100 * time.Millisecond)
 }
+
+func TestWebContainer_NotFound(t *testing.T) {
+
+	testRun := func(c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.SetFilters(&testcases.LogFilter{})
+
+		c.SetNotFoundHandler(func(webCtx SpringWeb.WebContext) {
+			webCtx.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		})
+
+		c.SetMethodNotAllowedHandler(func(webCtx SpringWeb.WebContext) {
+			webCtx.JSON(http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		})
+
+		c.GET("/pets/{id}", func(webCtx SpringWeb.WebContext) {
+			webCtx.String(http.StatusOK, webCtx.PathParam("id"))
+		})
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		resp, _ := http.Get("http://127.0.0.1:8080/none")
+		body, _ := ioutil.ReadAll(resp.Body)
+		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+		assert.Equal(t, strings.TrimSpace(string(body)), `{"error":"not found"}`)
+
+		resp, _ = http.Post("http://127.0.0.1:8080/pets/1", SpringWeb.MIMEApplicationJSON, nil)
+		body, _ = ioutil.ReadAll(resp.Body)
+		assert.Equal(t, resp.StatusCode, http.StatusMethodNotAllowed)
+		assert.Equal(t, resp.Header.Get(SpringWeb.HeaderAllow), "GET, OPTIONS")
+		assert.Equal(t, strings.TrimSpace(string(body)), `{"error":"method not allowed"}`)
+
+		req, _ := http.NewRequest(http.MethodOptions, "http://127.0.0.1:8080/pets/1", nil)
+		resp, _ = http.DefaultClient.Do(req)
+		assert.Equal(t, resp.StatusCode, http.StatusNoContent)
+		assert.Equal(t, resp.Header.Get(SpringWeb.HeaderAllow), "GET, OPTIONS")
+
+		c.Stop(context.TODO())
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(SpringEcho.NewContainer())
+	})
+}
+
+func TestWebMapping_Mount(t *testing.T) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
+	})
+
+	testRun := func(c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		var wildcard string
+		c.SetFilters(&testcases.LogFilter{}, &testcases.FuncFilter{
+			Fn: func(webCtx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+				wildcard = webCtx.PathParam("*")
+				chain.Next(webCtx)
+			},
+		})
+
+		c.Mount("/admin/", mux)
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		resp, _ := http.Get("http://127.0.0.1:8080/admin/users/1")
+		body, _ := ioutil.ReadAll(resp.Body)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		assert.Equal(t, string(body), "GET /users/1")
+		assert.Equal(t, wildcard, "users/1")
+
+		resp, _ = http.PostForm("http://127.0.0.1:8080/admin/", nil)
+		body, _ = ioutil.ReadAll(resp.Body)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		assert.Equal(t, string(body), "POST /")
+
+		c.Stop(context.TODO())
+		time.Sleep(time.Millisecond * 50)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(SpringEcho.NewContainer())
+	})
+}
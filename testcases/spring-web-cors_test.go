@@ -0,0 +1,144 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-echo"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSFilter(t *testing.T) {
+
+	do := func(method, path string, header map[string]string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, "http://127.0.0.1:8080"+path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		cors := SpringWeb.NewCORSFilter().
+			AllowOrigins("https://a.com", "https://*.b.com").
+			AllowOriginPatterns(`^https://c[0-9]\.com$`).
+			AllowOriginFunc(func(origin string) bool { return origin == "https://d.com" }).
+			AllowMethods(http.MethodGet, http.MethodPost).
+			AllowCredentials(true).
+			ExposeHeaders("X-Total").
+			MaxAge(10 * time.Minute)
+		c.AddFilter(cors)
+
+		c.GET("/users", func(ctx SpringWeb.WebContext) {
+			ctx.String(http.StatusOK, "users")
+		})
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		// 简单请求
+		resp, body := do(http.MethodGet, "/users", map[string]string{"Origin": "https://a.com"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "users", strings.TrimSpace(body))
+		assert.Equal(t, "https://a.com", resp.Header.Get(SpringWeb.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, "true", resp.Header.Get(SpringWeb.HeaderAccessControlAllowCredentials))
+		assert.Equal(t, "X-Total", resp.Header.Get(SpringWeb.HeaderAccessControlExposeHeaders))
+		assert.Equal(t, SpringWeb.HeaderOrigin, resp.Header.Get(SpringWeb.HeaderVary))
+
+		// 预检请求，没有注册 OPTIONS 方法
+		preflight := map[string]string{
+			"Origin":                         "https://x.y.b.com",
+			"Access-Control-Request-Method":  "POST",
+			"Access-Control-Request-Headers": "Content-Type, X-Token",
+		}
+		resp, _ = do(http.MethodOptions, "/users", preflight)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "https://x.y.b.com", resp.Header.Get(SpringWeb.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, "GET, POST", resp.Header.Get(SpringWeb.HeaderAccessControlAllowMethods))
+		assert.Equal(t, "Content-Type, X-Token", resp.Header.Get(SpringWeb.HeaderAccessControlAllowHeaders))
+		assert.Equal(t, "600", resp.Header.Get(SpringWeb.HeaderAccessControlMaxAge))
+
+		for _, origin := range []string{"https://c1.com", "https://d.com"} {
+			preflight["Origin"] = origin
+			resp, _ = do(http.MethodOptions, "/users", preflight)
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			assert.Equal(t, origin, resp.Header.Get(SpringWeb.HeaderAccessControlAllowOrigin))
+		}
+
+		// 不允许的来源
+		preflight["Origin"] = "https://evil.com/.b.com"
+		resp, _ = do(http.MethodOptions, "/users", preflight)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, "", resp.Header.Get(SpringWeb.HeaderAccessControlAllowOrigin))
+
+		resp, _ = do(http.MethodGet, "/users", map[string]string{"Origin": "https://evil.com"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "", resp.Header.Get(SpringWeb.HeaderAccessControlAllowOrigin))
+
+		// 不是跨域请求时 OPTIONS 仍然由容器自动应答
+		resp, _ = do(http.MethodOptions, "/users", nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, "GET, OPTIONS", resp.Header.Get(SpringWeb.HeaderAllow))
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
+
+func TestCORSFilter_Wildcard(t *testing.T) {
+
+	// 允许所有来源时不能携带凭证
+	assert.Panics(t, func() { SpringWeb.NewCORSFilter().AllowOrigins("*").AllowCredentials(true) })
+	assert.Panics(t, func() { SpringWeb.NewCORSFilter().AllowCredentials(true).AllowOrigins("*") })
+
+	f := SpringWeb.NewCORSFilter().AllowOrigins("*")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(SpringWeb.HeaderOrigin, "https://evil.com")
+	rec := serveEcho(req, func(ctx SpringWeb.WebContext) {
+		ctx.NoContent(http.StatusOK)
+	}, f)
+	assert.Equal(t, "*", rec.Header().Get(SpringWeb.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "", rec.Header().Get(SpringWeb.HeaderAccessControlAllowCredentials))
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapCSRFStore 使用 map 模拟 Session 的 CSRFTokenStore
+type mapCSRFStore map[string]string
+
+func (s mapCSRFStore) Load(ctx SpringWeb.WebContext) string {
+	return s[ctx.Request().Header.Get("X-Session")]
+}
+
+func (s mapCSRFStore) Save(ctx SpringWeb.WebContext, token string) {
+	s[ctx.Request().Header.Get("X-Session")] = token
+}
+
+func TestCSRFFilter(t *testing.T) {
+
+	var token string
+	handler := func(ctx SpringWeb.WebContext) {
+		token = ctx.Get(SpringWeb.CSRFKey).(string)
+		ctx.String(http.StatusOK, "ok")
+	}
+
+	t.Run("double submit cookie", func(t *testing.T) {
+		f := SpringWeb.NewCSRFFilter()
+
+		// 安全方法不校验，并下发 token
+		rec := serveEcho(httptest.NewRequest(http.MethodGet, "/form", nil), handler, f)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		cookies := rec.Result().Cookies()
+		assert.Equal(t, 1, len(cookies))
+		assert.Equal(t, "_csrf", cookies[0].Name)
+		assert.Equal(t, token, cookies[0].Value)
+
+		// 没有提交 token
+		req := httptest.NewRequest(http.MethodPost, "/form", nil)
+		req.AddCookie(cookies[0])
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get(SpringWeb.HeaderContentType))
+
+		// token 不匹配
+		req = httptest.NewRequest(http.MethodPost, "/form", nil)
+		req.AddCookie(cookies[0])
+		req.Header.Set("X-CSRF-Token", "bad")
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		// 通过请求头提交
+		req = httptest.NewRequest(http.MethodPost, "/form", nil)
+		req.AddCookie(cookies[0])
+		req.Header.Set("X-CSRF-Token", cookies[0].Value)
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// 通过表单提交
+		form := url.Values{"_csrf": {cookies[0].Value}}
+		req = httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(form.Encode()))
+		req.Header.Set(SpringWeb.HeaderContentType, SpringWeb.MIMEApplicationForm)
+		req.AddCookie(cookies[0])
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// 不在查找范围内的位置
+		req = httptest.NewRequest(http.MethodPost, "/form?_csrf="+cookies[0].Value, nil)
+		req.AddCookie(cookies[0])
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		f.WithTokenLookup("query:_csrf")
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		assert.Panics(t, func() { f.WithTokenLookup("cookie:_csrf") })
+	})
+
+	t.Run("synchronizer token", func(t *testing.T) {
+		store := mapCSRFStore{}
+		f := SpringWeb.NewCSRFFilter().WithStore(store)
+		assert.Equal(t, SpringWeb.CSRFSynchronizerToken, f.Mode())
+
+		req := httptest.NewRequest(http.MethodGet, "/form", nil)
+		req.Header.Set("X-Session", "s1")
+		rec := serveEcho(req, handler, f)
+		assert.Equal(t, 0, len(rec.Result().Cookies()))
+		assert.Equal(t, store["s1"], token)
+
+		req = httptest.NewRequest(http.MethodPost, "/form", nil)
+		req.Header.Set("X-Session", "s2")
+		req.Header.Set("X-CSRF-Token", store["s1"])
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		req.Header.Set("X-Session", "s1")
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
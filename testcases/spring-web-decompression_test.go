@@ -0,0 +1,134 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+// compress 使用指定的编码压缩数据
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		var err error
+		w, err = flate.NewWriter(&buf, flate.DefaultCompression)
+		assert.NoError(t, err)
+	}
+	_, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDecompressionFilter(t *testing.T) {
+
+	f := SpringWeb.NewDecompressionFilter().WithMaxSize(4096)
+	body := []byte(`{"name":"jim","tags":["` + strings.Repeat("a", 1000) + `"]}`)
+
+	type Request struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	for _, engine := range []struct {
+		name  string
+		serve func(*http.Request, SpringWeb.Handler, ...SpringWeb.Filter) *httptest.ResponseRecorder
+	}{
+		{"gin", serveGin},
+		{"echo", serveEcho},
+	} {
+		t.Run(engine.name, func(t *testing.T) {
+
+			var bound Request
+			bind := func(ctx SpringWeb.WebContext) {
+				bound = Request{}
+				if err := ctx.Bind(&bound); err != nil {
+					ctx.String(http.StatusBadRequest, err.Error())
+					return
+				}
+				ctx.NoContent(http.StatusOK)
+			}
+
+			serve := func(encoding string, data []byte, fn SpringWeb.Handler) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+				req.Header.Set("Content-Type", SpringWeb.MIMEApplicationJSON)
+				if encoding != "" {
+					req.Header.Set("Content-Encoding", encoding)
+				}
+				return engine.serve(req, fn, f)
+			}
+
+			for _, encoding := range []string{"gzip", "deflate", "raw-deflate"} {
+				header := strings.TrimPrefix(encoding, "raw-")
+				rec := serve(header, compress(t, encoding, body), bind)
+				assert.Equal(t, http.StatusOK, rec.Code, encoding)
+				assert.Equal(t, "jim", bound.Name, encoding)
+			}
+
+			// 多重编码按照相反的顺序解压
+			data := compress(t, "gzip", compress(t, "deflate", body))
+			rec := serve("deflate, gzip", data, func(ctx SpringWeb.WebContext) {
+				b, err := ctx.GetRawData()
+				assert.NoError(t, err)
+				assert.Equal(t, body, b)
+				assert.Empty(t, ctx.GetHeader("Content-Encoding"))
+				assert.Equal(t, int64(len(body)), ctx.Request().ContentLength)
+				ctx.NoContent(http.StatusOK)
+			})
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			rec = serve("", body, bind)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "jim", bound.Name)
+
+			rec = serve("identity", body, bind)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			rec = serve("br", body, bind)
+			assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+			assert.Equal(t, "gzip, deflate", rec.Header().Get("Accept-Encoding"))
+			assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get("Content-Type"))
+
+			// 压缩炸弹
+			bomb := compress(t, "gzip", make([]byte, 1<<20))
+			assert.True(t, len(bomb) < 4096)
+			rec = serve("gzip", bomb, bind)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+			rec = serve("gzip", []byte("not gzip"), bind)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-parent/spring-error"
+	"github.com/go-spring/go-spring-web/spring-echo"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebContainer_ErrorRenderer(t *testing.T) {
+
+	get := func(path string, accept string) (int, string, string) {
+		req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:8080"+path, nil)
+		if accept != "" {
+			req.Header.Set(SpringWeb.HeaderAccept, accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get(SpringWeb.HeaderContentType), strings.TrimSpace(string(body))
+	}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.GET("/ok", SpringWeb.ERR(func(ctx SpringWeb.WebContext) error {
+			ctx.String(http.StatusOK, "ok")
+			return nil
+		}))
+
+		c.GET("/http", SpringWeb.ERR(func(ctx SpringWeb.WebContext) error {
+			return SpringWeb.NewHTTPError(http.StatusBadRequest, "invalid name").WithDetails("name")
+		}))
+
+		c.GET("/details", SpringWeb.ERR(func(ctx SpringWeb.WebContext) error {
+			return SpringWeb.NewHTTPError(http.StatusBadRequest, "bad").WithDetails(map[string]string{"name": "required"})
+		}))
+
+		c.GET("/error", SpringWeb.ERR(func(ctx SpringWeb.WebContext) error {
+			return errors.New("db password is 123456")
+		}))
+
+		c.GET("/rpc", func(ctx SpringWeb.WebContext) {
+			SpringError.ERROR.Panic(errors.New("rpc error")).When(true)
+		})
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		code, _, body := get("/ok", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", body)
+
+		code, contentType, body := get("/http", "")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, contentType, SpringWeb.MIMEApplicationJSON)
+		assert.JSONEq(t, `{"code":400,"message":"invalid name","details":"name"}`, body)
+
+		code, contentType, body = get("/http", "application/xml")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, contentType, SpringWeb.MIMEApplicationXML)
+		assert.Contains(t, body, "<error><code>400</code><message>invalid name</message><details>name</details></error>")
+
+		// map 无法编码成 XML，使用 JSON 格式
+		code, contentType, body = get("/details", "application/xml")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, contentType, SpringWeb.MIMEApplicationJSON)
+		assert.JSONEq(t, `{"code":400,"message":"bad","details":{"name":"required"}}`, body)
+
+		// 不向客户端暴露错误信息
+		code, _, body = get("/error", "")
+		assert.Equal(t, http.StatusInternalServerError, code)
+		assert.JSONEq(t, `{"code":500,"message":"Internal Server Error"}`, body)
+
+		code, _, body = get("/rpc", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, `"Code":-1`)
+		assert.Contains(t, body, "rpc error")
+
+		// 自定义 ErrorRenderer
+		c.SetErrorRenderer(func(ctx SpringWeb.WebContext, err error) {
+			ctx.String(http.StatusTeapot, err.Error())
+		})
+		code, _, body = get("/http", "")
+		assert.Equal(t, http.StatusTeapot, code)
+		assert.Equal(t, "code=400, message=invalid name", body)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
+
+func TestWebContext_Problem(t *testing.T) {
+
+	type EchoRequest struct {
+		Age int `query:"age" form:"age"`
+	}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.GET("/problem", SpringWeb.ERR(func(ctx SpringWeb.WebContext) error {
+			return SpringWeb.NewProblem(http.StatusConflict).WithDetail("exists").With("id", "1")
+		}))
+
+		c.GET("/bind", SpringWeb.BIND(func(req EchoRequest) interface{} {
+			return req.Age
+		}))
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		get := func(path string) (int, string, string) {
+			resp, err := http.Get("http://127.0.0.1:8080" + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			return resp.StatusCode, resp.Header.Get(SpringWeb.HeaderContentType), string(body)
+		}
+
+		code, contentType, body := get("/problem")
+		assert.Equal(t, http.StatusConflict, code)
+		assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, contentType)
+		assert.JSONEq(t, `{"type":"about:blank","title":"Conflict","status":409,"detail":"exists","id":"1"}`, body)
+
+		code, _, body = get("/bind?age=3")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, `"Data":3`)
+
+		code, contentType, body = get("/bind?age=abc")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, contentType)
+		assert.Contains(t, body, `"title":"Bad Request"`)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
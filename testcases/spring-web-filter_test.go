@@ -19,6 +19,10 @@ package testcases_test
 import (
 	"container/list"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-spring/go-spring-parent/spring-logger"
@@ -26,6 +30,7 @@ import (
 	"github.com/go-spring/go-spring-web/spring-echo"
 	"github.com/go-spring/go-spring-web/spring-web"
 	"github.com/go-spring/go-spring-web/testcases"
+	"github.com/labstack/echo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,3 +53,303 @@ func TestFilterChain(t *testing.T) {
 
 	assert.Equal(t, SpringUtils.NewList(2, 5, 5, 2), l)
 }
+
+func TestFilterRegistration(t *testing.T) {
+
+	l := list.New()
+	f1 := testcases.NewNumberFilter(1, l)
+	f2 := testcases.NewNumberFilter(2, l)
+	f3 := testcases.NewNumberFilter(3, l)
+	f4 := testcases.NewNumberFilter(4, l)
+	f5 := testcases.NewNumberFilter(5, l)
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.SetFilters(f1)
+
+	c.AddFilter(f2).Include("/api/**").Exclude("/api/login")
+	c.AddFilter(f3).Include("/api/**").WithMethod(SpringWeb.MethodPost)
+	c.AddFilter(f4).WithOrder(-1)
+
+	login := c.POST("/api/login", nil, f5)
+	pets := c.Request(SpringWeb.MethodGetPost, "/api/pets/{id}", nil)
+	home := c.GET("/", nil)
+
+	assert.Equal(t, []SpringWeb.Filter{f4, f1, f3, f5}, c.ResolveFilters(login, http.MethodPost))
+	assert.Equal(t, []SpringWeb.Filter{f4, f1, f2}, c.ResolveFilters(pets, http.MethodGet))
+	assert.Equal(t, []SpringWeb.Filter{f4, f1, f2, f3}, c.ResolveFilters(pets, http.MethodPost))
+	assert.Equal(t, []SpringWeb.Filter{f4, f1}, c.ResolveFilters(home, http.MethodGet))
+	assert.Equal(t, []SpringWeb.Filter{f4, f1}, c.ResolveFilters(nil, ""))
+}
+
+type orderedFilter struct {
+	*testcases.NumberFilter
+	name  string
+	order int
+}
+
+func (f *orderedFilter) Name() string {
+	return f.name
+}
+
+func (f *orderedFilter) Order() int {
+	return f.order
+}
+
+func TestFilterOrder(t *testing.T) {
+
+	l := list.New()
+	f1 := testcases.NewNumberFilter(1, l)
+	f2 := &orderedFilter{testcases.NewNumberFilter(2, l), "auth", 10}
+	f3 := &orderedFilter{testcases.NewNumberFilter(3, l), "trace", -10}
+	f4 := testcases.NewNumberFilter(4, l)
+	f5 := &orderedFilter{testcases.NewNumberFilter(5, l), "route", 5}
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.SetFilters(f1, f2)
+	c.AddFilter(f3)
+	c.AddFilter(f4).WithName("log").WithOrder(20)
+
+	assert.Equal(t, f2, c.GetFilterRegistration("auth").Filter())
+	assert.Equal(t, f4, c.GetFilterRegistration("log").Filter())
+	assert.Nil(t, c.GetFilterRegistration("none"))
+
+	// 路由的过滤器可以插入到容器的过滤器之前
+	get := c.GET("/get", nil, f5)
+	assert.Equal(t, []SpringWeb.Filter{f3, f1, f5, f2, f4}, c.ResolveFilters(get, http.MethodGet))
+
+	get.DisableFilters("auth", "log")
+	assert.Equal(t, []SpringWeb.Filter{f3, f1, f5}, c.ResolveFilters(get, http.MethodGet))
+
+	c.GetFilterRegistration("trace").WithOrder(30)
+	assert.Equal(t, []SpringWeb.Filter{f1, f5, f3}, c.ResolveFilters(get, http.MethodGet))
+
+	c.AddFilter(f1).WithName("auth")
+	assert.Panics(t, func() { c.PreStart() })
+}
+
+// serveEcho 不启动服务器，直接使用 echo 的上下文执行 Web 处理函数
+func serveEcho(req *http.Request, fn SpringWeb.Handler, filters ...SpringWeb.Filter) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	echoCtx := echo.New().NewContext(req, rec)
+	echoCtx.Reset(req, rec)
+	_ = SpringEcho.HandlerWrapper(fn, filters)(echoCtx)
+	return rec
+}
+
+func TestFilterChain_Interrupt(t *testing.T) {
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.SetFilters(&testcases.InterruptFilter{})
+	get := c.GET("/get", nil)
+
+	serve := func(filters []SpringWeb.Filter) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/get", nil)
+		return serveEcho(req, func(ctx SpringWeb.WebContext) {
+			ctx.String(http.StatusOK, "ok")
+		}, filters...)
+	}
+
+	var invoked bool
+	var status, size int
+	post := &testcases.FuncFilter{Fn: func(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+		chain.Next(ctx)
+		invoked = chain.Invoked()
+		status, size = ctx.ResponseStatus(), ctx.ResponseSize()
+	}}
+
+	// 处理函数正常执行
+	rec := serve([]SpringWeb.Filter{post})
+	assert.True(t, invoked)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, size)
+
+	// 默认只打印警告日志
+	rec = serve(c.ChainFilters(get, http.MethodGet))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", rec.Body.String())
+
+	c.SetInterruptPolicy(SpringWeb.InterruptError)
+	rec = serve(c.ChainFilters(get, http.MethodGet))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// 过滤器自己写入了响应
+	c.SetFilters(&testcases.FuncFilter{Fn: func(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+		ctx.NoContent(http.StatusUnauthorized)
+	}})
+	rec = serve(c.ChainFilters(get, http.MethodGet))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAccessLogFilter(t *testing.T) {
+
+	var lines []string
+	var entries []*SpringWeb.AccessLogEntry
+	output := func(ctx SpringWeb.WebContext, entry *SpringWeb.AccessLogEntry, line string) {
+		lines = append(lines, line)
+		entries = append(entries, entry)
+	}
+
+	hello := func(ctx SpringWeb.WebContext) {
+		ctx.String(http.StatusCreated, "hello")
+	}
+
+	f := SpringWeb.NewAccessLogFilter().WithOutput(output).Skip("/health/**")
+
+	req := httptest.NewRequest(http.MethodGet, "/hello?a=1", nil)
+	req.Header.Set(SpringWeb.HeaderXRequestID, "abc")
+	req.Header.Set("User-Agent", "test")
+	serveEcho(req, hello, f)
+
+	assert.Equal(t, 1, len(lines))
+	assert.Equal(t, http.StatusCreated, entries[0].Status)
+	assert.Equal(t, 5, entries[0].Bytes)
+	assert.Equal(t, "/hello?a=1", entries[0].URI)
+	assert.Equal(t, "abc", entries[0].RequestID)
+	assert.Regexp(t, `^192\.0\.2\.1 - - \[.+\] "GET /hello\?a=1 HTTP/1\.1" 201 5 "" "test" [0-9.]+ms "" abc$`, lines[0])
+
+	// 健康检查接口不输出日志
+	serveEcho(httptest.NewRequest(http.MethodGet, "/health/live", nil), hello, f)
+	assert.Equal(t, 1, len(lines))
+
+	// 采样率为 0 时只输出 5xx 响应
+	f.WithSampleRate(0).WithFormat(SpringWeb.AccessLogJSON)
+	serveEcho(httptest.NewRequest(http.MethodGet, "/hello", nil), hello, f)
+	assert.Equal(t, 1, len(lines))
+
+	serveEcho(httptest.NewRequest(http.MethodGet, "/hello", nil), func(ctx SpringWeb.WebContext) {
+		ctx.String(http.StatusBadGateway, "error")
+	}, f)
+	assert.Equal(t, 2, len(lines))
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, float64(http.StatusBadGateway), entry["status"])
+	assert.Equal(t, "GET", entry["method"])
+
+	// 处理函数 panic 时按照 500 输出日志
+	rec := serveEcho(httptest.NewRequest(http.MethodGet, "/panic", nil), func(ctx SpringWeb.WebContext) {
+		panic("boom")
+	}, f)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, 3, len(lines))
+	assert.Equal(t, http.StatusInternalServerError, entries[2].Status)
+	assert.Equal(t, "/panic", entries[2].URI)
+}
+
+// captureLogger 记录 INFO 日志的 StdLogger
+type captureLogger struct {
+	SpringLogger.StdLogger
+	lines []string
+}
+
+func (l *captureLogger) Info(args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(args...))
+}
+
+func (l *captureLogger) Infof(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestRequestIDFilter(t *testing.T) {
+
+	logger := &captureLogger{}
+	defer func(fn func(context.Context, ...string) SpringLogger.StdLogger) {
+		SpringLogger.Logger = fn
+	}(SpringLogger.Logger)
+	SpringLogger.Logger = func(ctx context.Context, tags ...string) SpringLogger.StdLogger {
+		return logger
+	}
+
+	var fromCtx, fromLogCtx string
+	handler := func(ctx SpringWeb.WebContext) {
+		fromCtx = SpringWeb.RequestID(ctx)
+		fromLogCtx = SpringWeb.RequestIDFromContext(ctx)
+		logger.lines = nil
+		ctx.LogInfo("hello")
+		ctx.LogInfof("hello %d", 1)
+		ctx.Logger("tag").Info("world")
+		ctx.Logger().Infof("world %d", 2)
+		ctx.NoContent(http.StatusOK)
+	}
+
+	f := SpringWeb.NewRequestIDFilter()
+
+	// 使用客户端传入的请求 ID
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(SpringWeb.HeaderXRequestID, "abc")
+	rec := serveEcho(req, handler, f)
+	assert.Equal(t, "abc", rec.Header().Get(SpringWeb.HeaderXRequestID))
+	assert.Equal(t, "abc", fromCtx)
+	assert.Equal(t, "abc", fromLogCtx)
+
+	// 每一行日志都带有请求 ID
+	assert.Equal(t, []string{"[abc] hello", "[abc] hello 1", "[abc] world", "[abc] world 2"}, logger.lines)
+
+	// 生成新的请求 ID
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(SpringWeb.HeaderXRequestID, "a\nb")
+	rec = serveEcho(req, handler, f)
+	id := rec.Header().Get(SpringWeb.HeaderXRequestID)
+	assert.Regexp(t, "^[0-9a-f]{32}$", id)
+	assert.Equal(t, id, fromCtx)
+	assert.Equal(t, id, fromLogCtx)
+	assert.Equal(t, "["+id+"] world", logger.lines[2])
+
+	// 访问日志使用同一个请求 ID
+	var entry *SpringWeb.AccessLogEntry
+	accessLog := SpringWeb.NewAccessLogFilter().WithOutput(
+		func(ctx SpringWeb.WebContext, e *SpringWeb.AccessLogEntry, line string) {
+			entry = e
+		})
+	f.WithHeader("X-Trace-ID").WithGenerator(func() string { return "gen" })
+	rec = serveEcho(httptest.NewRequest(http.MethodGet, "/", nil), handler, accessLog, f)
+	assert.Equal(t, "gen", rec.Header().Get("X-Trace-ID"))
+	assert.Equal(t, "gen", entry.RequestID)
+}
+
+func TestWebContainer_Recovery(t *testing.T) {
+
+	c := SpringWeb.NewBaseWebContainer()
+	get := c.GET("/get", nil)
+
+	panicHandler := func(ctx SpringWeb.WebContext) {
+		panic("boom")
+	}
+
+	// 默认返回 500
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	rec := serveEcho(req, panicHandler, c.ChainFilters(get, http.MethodGet)...)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get(SpringWeb.HeaderContentType))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500}`, rec.Body.String())
+
+	// 自定义错误响应
+	var value interface{}
+	var stack []byte
+	c.SetRecoveryHandler(func(ctx SpringWeb.WebContext, err interface{}, s []byte) {
+		value, stack = err, s
+		if !ctx.Written() {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{"error": err})
+		}
+	})
+	rec = serveEcho(req, panicHandler, c.ChainFilters(get, http.MethodGet)...)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"error":"boom"}`, rec.Body.String())
+	assert.Equal(t, "boom", value)
+	assert.Contains(t, string(stack), "TestWebContainer_Recovery")
+
+	// 响应已经写入时不再重复写入
+	rec = serveEcho(req, func(ctx SpringWeb.WebContext) {
+		ctx.String(http.StatusOK, "partial")
+		panic("boom")
+	}, c.ChainFilters(get, http.MethodGet)...)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "partial", rec.Body.String())
+
+	// http.ErrAbortHandler 交给 net/http 处理
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serveEcho(req, func(ctx SpringWeb.WebContext) {
+			panic(http.ErrAbortHandler)
+		}, c.ChainFilters(get, http.MethodGet)...)
+	})
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveGin 使用 gin 的适配器执行过滤器和处理函数
+func serveGin(req *http.Request, fn SpringWeb.Handler, filters ...SpringWeb.Filter) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	ginCtx, _ := gin.CreateTestContext(rec)
+	ginCtx.Request = req
+	SpringGin.HandlerWrapper(req.URL.Path, fn, filters)(ginCtx)
+	return rec
+}
+
+func TestWebContext_TrustedProxies(t *testing.T) {
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.SetTrustedProxies(SpringWeb.NewTrustedProxies("10.0.0.0/8", "fd00::/8"))
+	filters := c.ChainFilters(c.GET("/", nil), http.MethodGet)
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		clientIP   string
+		scheme     string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "203.0.113.9:1234",
+			clientIP:   "203.0.113.9",
+			scheme:     "http",
+		},
+		{
+			name:       "spoofed",
+			remoteAddr: "203.0.113.9:1234",
+			header: http.Header{
+				"X-Forwarded-For":   {"1.2.3.4"},
+				"X-Real-Ip":         {"1.2.3.4"},
+				"X-Forwarded-Proto": {"https"},
+				"Forwarded":         {"for=1.2.3.4;proto=https"},
+			},
+			clientIP: "203.0.113.9",
+			scheme:   "http",
+		},
+		{
+			name:       "x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"X-Forwarded-For":   {"1.2.3.4, 198.51.100.7, 10.0.0.2"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			clientIP: "198.51.100.7",
+			scheme:   "https",
+		},
+		{
+			name:       "all trusted",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"10.0.0.3", "10.0.0.2"}},
+			clientIP:   "10.0.0.3",
+			scheme:     "http",
+		},
+		{
+			name:       "x-real-ip",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Real-Ip": {"198.51.100.7"}},
+			clientIP:   "198.51.100.7",
+			scheme:     "http",
+		},
+		{
+			name:       "forwarded",
+			remoteAddr: "[fd00::1]:1234",
+			header: http.Header{
+				"Forwarded":       {`for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.2;proto=http`},
+				"X-Forwarded-For": {"1.2.3.4"},
+			},
+			clientIP: "2001:db8:cafe::17",
+			scheme:   "https",
+		},
+		{
+			name:       "forwarded unknown",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"Forwarded": {"for=unknown;proto=https, for=10.0.0.2"}},
+			clientIP:   "10.0.0.2",
+			scheme:     "https",
+		},
+	}
+
+	for _, engine := range []struct {
+		name  string
+		serve func(*http.Request, SpringWeb.Handler, ...SpringWeb.Filter) *httptest.ResponseRecorder
+	}{
+		{"gin", serveGin},
+		{"echo", serveEcho},
+	} {
+		for _, tc := range testCases {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			var clientIP, scheme string
+			engine.serve(req, func(ctx SpringWeb.WebContext) {
+				clientIP, scheme = ctx.ClientIP(), ctx.Scheme()
+				ctx.NoContent(http.StatusOK)
+			}, filters...)
+			assert.Equal(t, tc.clientIP, clientIP, engine.name+" "+tc.name)
+			assert.Equal(t, tc.scheme, scheme, engine.name+" "+tc.name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPFilter(t *testing.T) {
+
+	f := SpringWeb.NewIPFilter().
+		Allow("192.168.10.0/24", "2001:db8:10::/48").
+		Deny("192.168.10.99")
+
+	c := SpringWeb.NewBaseWebContainer()
+	c.SetTrustedProxies(SpringWeb.NewTrustedProxies("10.0.0.1"))
+	admin := c.Route("/admin", f).GET("/users", nil)
+	public := c.GET("/users", nil)
+
+	serve := func(mapper *SpringWeb.Mapper, remoteAddr string, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, mapper.Path(), nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		return serveEcho(req, func(ctx SpringWeb.WebContext) {
+			ctx.NoContent(http.StatusOK)
+		}, c.ChainFilters(mapper, http.MethodGet)...).Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(admin, "192.168.10.5:1234", ""))
+	assert.Equal(t, http.StatusOK, serve(admin, "[2001:db8:10::5]:1234", ""))
+	assert.Equal(t, http.StatusForbidden, serve(admin, "192.168.10.99:1234", ""))
+	assert.Equal(t, http.StatusForbidden, serve(admin, "192.168.11.5:1234", ""))
+	assert.Equal(t, http.StatusForbidden, serve(admin, "[2001:db8:11::5]:1234", ""))
+	assert.Equal(t, http.StatusOK, serve(public, "192.168.11.5:1234", ""))
+
+	// 只信任受信任代理转发的客户端 IP
+	assert.Equal(t, http.StatusOK, serve(admin, "10.0.0.1:1234", "192.168.10.5"))
+	assert.Equal(t, http.StatusForbidden, serve(admin, "10.0.0.2:1234", "192.168.10.5"))
+	assert.Equal(t, http.StatusForbidden, serve(admin, "192.168.11.5:1234", "192.168.10.5"))
+
+	// 运行时重新加载规则
+	assert.Error(t, f.Reload([]string{"192.168.11.0/33"}, nil))
+	assert.Equal(t, http.StatusOK, serve(admin, "192.168.10.5:1234", ""))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve(admin, "192.168.10.5:1234", "")
+		}()
+	}
+	assert.NoError(t, f.Reload([]string{"192.168.11.0/24"}, nil))
+	wg.Wait()
+
+	assert.Equal(t, http.StatusForbidden, serve(admin, "192.168.10.5:1234", ""))
+	assert.Equal(t, http.StatusOK, serve(admin, "192.168.11.5:1234", ""))
+	assert.Equal(t, http.StatusOK, serve(admin, "192.168.11.99:1234", ""))
+
+	// 只有拒绝列表时允许其他全部 IP
+	assert.NoError(t, f.Reload(nil, []string{"::ffff:192.168.0.0/112"}))
+	assert.Equal(t, http.StatusForbidden, serve(admin, "192.168.1.1:1234", ""))
+	assert.Equal(t, http.StatusOK, serve(admin, "172.16.0.1:1234", ""))
+
+	assert.Panics(t, func() { SpringWeb.NewIPFilter().Allow("office") })
+}
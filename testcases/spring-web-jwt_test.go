@@ -0,0 +1,299 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+// signJWT 使用 key 对 claims 签名，key 可以是 []byte、*rsa.PrivateKey 和 *ecdsa.PrivateKey
+func signJWT(t *testing.T, alg string, kid string, key interface{}, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	input := encode(map[string]string{"alg": alg, "typ": "JWT", "kid": kid}) + "." + encode(claims)
+	hash := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, hash[:])
+		assert.NoError(t, err)
+		sig = make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTFilter(t *testing.T) {
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	keys := SpringWeb.NewStaticKeySet().
+		AddKey("hs", secret).
+		AddKey("rs", &rsaKey.PublicKey).
+		AddKey("es", &ecKey.PublicKey)
+
+	assert.Panics(t, func() { keys.AddKey("bad", "secret") })
+
+	f := SpringWeb.NewJWTFilter(keys).
+		WithIssuer("https://issuer").
+		WithAudience("api").
+		WithClockSkew(time.Minute).
+		WithCookie("jwt")
+
+	var principal *SpringWeb.Authentication
+	var claims SpringWeb.JWTClaims
+	handler := func(ctx SpringWeb.WebContext) {
+		principal = SpringWeb.Principal(ctx)
+		claims = SpringWeb.JWTClaimsFrom(ctx)
+		ctx.NoContent(http.StatusOK)
+	}
+
+	serve := func(token string, cookie bool) *httptest.ResponseRecorder {
+		principal, claims = nil, nil
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookie {
+			req.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return serveEcho(req, handler, f)
+	}
+
+	now := time.Now().Unix()
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub":   "jim",
+			"iss":   "https://issuer",
+			"aud":   []string{"web", "api"},
+			"exp":   now + 60,
+			"nbf":   now,
+			"scope": "read write",
+			"roles": []string{"admin"},
+		}
+	}
+
+	t.Run("algorithms", func(t *testing.T) {
+		for _, c := range []struct {
+			alg, kid string
+			key      interface{}
+		}{
+			{"HS256", "hs", secret},
+			{"RS256", "rs", rsaKey},
+			{"ES256", "es", ecKey},
+		} {
+			rec := serve(signJWT(t, c.alg, c.kid, c.key, valid()), false)
+			assert.Equal(t, http.StatusOK, rec.Code, c.alg)
+			assert.Equal(t, "jim", principal.Name)
+			assert.Equal(t, "Bearer", principal.Scheme)
+			assert.Equal(t, []string{"read", "write"}, principal.Scopes)
+			assert.True(t, principal.HasRole("admin"))
+			assert.Equal(t, "https://issuer", claims["iss"])
+		}
+	})
+
+	t.Run("cookie", func(t *testing.T) {
+		rec := serve(signJWT(t, "HS256", "hs", secret, valid()), true)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "jim", principal.Name)
+	})
+
+	t.Run("claims", func(t *testing.T) {
+		for name, modify := range map[string]func(c map[string]interface{}){
+			"expired":   func(c map[string]interface{}) { c["exp"] = now - 120 },
+			"not yet":   func(c map[string]interface{}) { c["nbf"] = now + 120 },
+			"issuer":    func(c map[string]interface{}) { c["iss"] = "https://other" },
+			"audience":  func(c map[string]interface{}) { c["aud"] = "web" },
+			"no expiry": nil,
+		} {
+			c := valid()
+			if modify == nil {
+				delete(c, "exp")
+				rec := serve(signJWT(t, "HS256", "hs", secret, c), false)
+				assert.Equal(t, http.StatusOK, rec.Code, name)
+				continue
+			}
+			modify(c)
+			rec := serve(signJWT(t, "HS256", "hs", secret, c), false)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+			assert.Nil(t, principal, name)
+		}
+
+		// 时钟误差范围内仍然有效
+		c := valid()
+		c["exp"] = now - 30
+		rec := serve(signJWT(t, "HS256", "hs", secret, c), false)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+
+		rec := serve("", true)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+		assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get("Content-Type"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec = serveEcho(req, handler, f)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = serve("a.b", false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		// 篡改声明
+		token := signJWT(t, "RS256", "rs", rsaKey, valid())
+		c := valid()
+		c["sub"] = "admin"
+		forged := signJWT(t, "RS256", "rs", rsaKey, c)
+		forgedParts := strings.Split(forged, ".")
+		tokenParts := strings.Split(token, ".")
+		rec = serve(forgedParts[0]+"."+forgedParts[1]+"."+tokenParts[2], false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		// 算法混淆：用 RSA 公钥作为 HMAC 密钥
+		pub, _ := json.Marshal(rsaKey.PublicKey)
+		rec = serve(signJWT(t, "HS256", "rs", pub, valid()), false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = serve(signJWT(t, "none", "hs", secret, valid()), false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = serve(signJWT(t, "HS256", "unknown", secret, valid()), false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		only := SpringWeb.NewJWTFilter(keys).WithAlgorithms("RS256")
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signJWT(t, "HS256", "hs", secret, valid()))
+		rec = serveEcho(req, handler, only)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestJWKSFile(t *testing.T) {
+
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	key2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	secret := []byte("secret")
+
+	b64 := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+
+	rsaJWK := fmt.Sprintf(`{"kty":"RSA","kid":"k1","use":"sig","n":"%s","e":"%s"}`,
+		b64(key1.N), b64(big.NewInt(int64(key1.E))))
+	ecJWK := fmt.Sprintf(`{"kty":"EC","kid":"k2","crv":"P-256","x":"%s","y":"%s"}`,
+		b64(key2.X), b64(key2.Y))
+	octJWK := fmt.Sprintf(`{"kty":"oct","kid":"k3","k":"%s"}`, base64.RawURLEncoding.EncodeToString(secret))
+	encJWK := `{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}`
+	okpJWK := `{"kty":"OKP","kid":"k5","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	p384JWK := `{"kty":"EC","kid":"k6","crv":"P-384","x":"AQ","y":"AQ"}`
+
+	dir, err := ioutil.TempDir("", "jwks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwks.json")
+
+	write := func(modTime time.Time, keys ...string) {
+		b := `{"keys":[`
+		for i, k := range keys {
+			if i > 0 {
+				b += ","
+			}
+			b += k
+		}
+		b += `]}`
+		assert.NoError(t, ioutil.WriteFile(path, []byte(b), 0644))
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	_, err = SpringWeb.NewJWKSFile(path, time.Hour)
+	assert.Error(t, err)
+
+	// 忽略不支持的密钥类型和曲线
+	write(time.Now().Add(-time.Hour), okpJWK, rsaJWK, p384JWK, encJWK)
+	keys, err := SpringWeb.NewJWKSFile(path, time.Hour)
+	assert.NoError(t, err)
+	_, err = keys.Key("k5")
+	assert.Error(t, err)
+
+	f := SpringWeb.NewJWTFilter(keys)
+	verify := func(token string) error {
+		_, err := f.Verify(token)
+		return err
+	}
+
+	claims := map[string]interface{}{"sub": "jim"}
+	assert.NoError(t, verify(signJWT(t, "RS256", "k1", key1, claims)))
+	assert.Error(t, verify(signJWT(t, "ES256", "k2", key2, claims)))
+
+	_, err = keys.Key("enc")
+	assert.Error(t, err)
+
+	// 轮换密钥，未知的 kid 会触发重新加载
+	write(time.Now(), ecJWK, okpJWK, octJWK)
+	assert.NoError(t, verify(signJWT(t, "ES256", "k2", key2, claims)))
+	assert.NoError(t, verify(signJWT(t, "HS256", "k3", secret, claims)))
+	assert.Error(t, verify(signJWT(t, "RS256", "k1", key1, claims)))
+
+	// 文件损坏时继续使用原来的密钥
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now().Add(time.Hour), time.Now().Add(time.Hour)))
+	assert.NoError(t, verify(signJWT(t, "ES256", "k2", key2, claims)))
+	assert.Error(t, verify(signJWT(t, "ES256", "k4", key2, claims)))
+
+	_, err = SpringWeb.ParseJWKS([]byte(`{"keys":[{"kty":"EC","kid":"bad","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+	assert.Error(t, err)
+}
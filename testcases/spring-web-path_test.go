@@ -0,0 +1,177 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-spring/go-spring-web/spring-echo"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebContainer_PathPolicy(t *testing.T) {
+
+	// 不自动跟随重定向，以便检查重定向的地址
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	get := func(path string) (int, string, string) {
+		resp, err := client.Get("http://127.0.0.1:8080" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), resp.Header.Get("Location")
+	}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.GET("/pets", func(webCtx SpringWeb.WebContext) {
+			webCtx.String(http.StatusOK, "pets")
+		})
+
+		c.GET("/users/{id}/", func(webCtx SpringWeb.WebContext) {
+			webCtx.String(http.StatusOK, webCtx.PathParam("id"))
+		})
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		t.Run("strict", func(t *testing.T) {
+			c.SetTrailingSlashPolicy(SpringWeb.TrailingSlashStrict)
+			code, _, _ := get("/pets/")
+			assert.Equal(t, http.StatusNotFound, code)
+			code, _, _ = get("/users/1")
+			assert.Equal(t, http.StatusNotFound, code)
+		})
+
+		t.Run("redirect", func(t *testing.T) {
+			c.SetTrailingSlashPolicy(SpringWeb.TrailingSlashRedirect)
+			code, _, location := get("/pets/?a=1")
+			assert.Equal(t, http.StatusMovedPermanently, code)
+			assert.Equal(t, "/pets?a=1", location)
+			code, _, location = get("/users/1")
+			assert.Equal(t, http.StatusMovedPermanently, code)
+			assert.Equal(t, "/users/1/", location)
+		})
+
+		t.Run("tolerate", func(t *testing.T) {
+			c.SetTrailingSlashPolicy(SpringWeb.TrailingSlashTolerate)
+			code, body, _ := get("/pets/")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "pets", body)
+			code, body, _ = get("/users/1")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "1", body)
+		})
+
+		t.Run("case insensitive", func(t *testing.T) {
+			c.SetTrailingSlashPolicy(SpringWeb.TrailingSlashTolerate)
+			code, _, _ := get("/PETS")
+			assert.Equal(t, http.StatusNotFound, code)
+			c.SetCaseInsensitive(true)
+			defer c.SetCaseInsensitive(false)
+			code, body, _ := get("/PETS")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "pets", body)
+			code, body, _ = get("/Users/Tom/")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "Tom", body)
+		})
+
+		t.Run("clean path", func(t *testing.T) {
+			c.SetTrailingSlashPolicy(SpringWeb.TrailingSlashStrict)
+			code, _, _ := get("//pets")
+			assert.Equal(t, http.StatusNotFound, code)
+			c.SetCleanPath(true)
+			defer c.SetCleanPath(false)
+			code, body, _ := get("//pets")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "pets", body)
+			code, body, _ = get("/users/../pets")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "pets", body)
+		})
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
+
+func TestGinContext_PathParam(t *testing.T) {
+
+	testCases := []struct {
+		path   string
+		names  []string
+		values []string
+		wild   string
+	}{
+		{
+			path:   "/users/42/files/a/b.txt",
+			names:  []string{"id", "*"},
+			values: []string{"42", "a/b.txt"},
+			wild:   "a/b.txt",
+		},
+		{
+			path:   "/users/alice/files/",
+			names:  []string{"id", "*"},
+			values: []string{"alice", ""},
+			wild:   "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			g := gin.New()
+			g.GET("/users/:id/files/*"+SpringGin.WildRouteName, SpringGin.HandlerWrapper("/users/{id}/files/*", func(webCtx SpringWeb.WebContext) {
+				assert.Equal(t, tc.values[0], webCtx.PathParam("id"))
+				assert.Equal(t, tc.wild, webCtx.PathParam("*"))
+				assert.Equal(t, tc.names, webCtx.PathParamNames())
+				assert.Equal(t, tc.values, webCtx.PathParamValues())
+				webCtx.NoContent(http.StatusOK)
+			}, nil))
+
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-echo"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxy(t *testing.T) {
+
+	newUpstream := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			// 简单的 WebSocket 回显服务
+			if r.Header.Get("Upgrade") == "websocket" {
+				conn, rw, _ := w.(http.Hijacker).Hijack()
+				defer conn.Close()
+				_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+				_ = rw.Flush()
+				line, _ := rw.ReadString('\n')
+				_, _ = rw.WriteString(name + ":" + line)
+				_ = rw.Flush()
+				return
+			}
+
+			_, _ = fmt.Fprintf(w, "%s %s?%s %s %s", name, r.URL.Path, r.URL.RawQuery,
+				r.Header.Get(SpringWeb.HeaderXForwardedProto), r.Header.Get(SpringWeb.HeaderXForwardedHost))
+		}))
+	}
+
+	u1 := newUpstream("u1")
+	defer u1.Close()
+
+	u2 := newUpstream("u2")
+	defer u2.Close()
+
+	u3 := newUpstream("u3")
+	u3.Close() // 模拟不可用的上游服务
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get("http://127.0.0.1:8080" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.GET("/pets/{id}", SpringWeb.Proxy(u1.URL+"/v1/pet/{id}?from=proxy", nil))
+		c.GET("/legacy/*", SpringWeb.Proxy(u1.URL, nil))
+		c.GET("/lb/*", SpringWeb.Proxy(u1.URL+"/*", &SpringWeb.ProxyOptions{
+			Upstreams: []string{u2.URL},
+		}))
+		c.GET("/failover", SpringWeb.Proxy(u3.URL, &SpringWeb.ProxyOptions{
+			Upstreams:   []string{u1.URL},
+			FailTimeout: time.Minute,
+		}))
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		code, body := get("/pets/7?a=1")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "u1 /v1/pet/7?from=proxy&a=1 http 127.0.0.1:8080", body)
+
+		code, body = get("/legacy/store/order")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "u1 /legacy/store/order? http 127.0.0.1:8080", body)
+
+		_, b1 := get("/lb/a")
+		_, b2 := get("/lb/a")
+		assert.ElementsMatch(t, []string{"u1 /a? http 127.0.0.1:8080", "u2 /a? http 127.0.0.1:8080"}, []string{b1, b2})
+
+		// 第一次请求失败后 u3 被摘除，后续请求全部转发到 u1
+		code, _ = get("/failover")
+		if code == http.StatusBadGateway {
+			code, _ = get("/failover")
+		}
+		assert.Equal(t, http.StatusOK, code)
+		for i := 0; i < 3; i++ {
+			code, body = get("/failover")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "u1 /failover? http 127.0.0.1:8080", body)
+		}
+
+		// WebSocket 升级
+		conn, err := net.Dial("tcp", "127.0.0.1:8080")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("GET /legacy/ws HTTP/1.1\r\nHost: 127.0.0.1:8080\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		_, _ = conn.Write([]byte("hello\n"))
+		line, _ := reader.ReadString('\n')
+		assert.Equal(t, "u1:hello\n", line)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
+
+func TestProxy_Forwarded(t *testing.T) {
+
+	u := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s|%s|%s", r.Header.Get(SpringWeb.HeaderXForwardedFor),
+			r.Header.Get(SpringWeb.HeaderForwarded), r.Header.Get(SpringWeb.HeaderXRealIP))
+	}))
+	defer u.Close()
+
+	serve := func(proxies *SpringWeb.TrustedProxies) string {
+		c := SpringWeb.NewBaseWebContainer()
+		c.SetTrustedProxies(proxies)
+		handler := SpringWeb.Proxy(u.URL, nil)
+		filters := c.ChainFilters(c.GET("/", handler), http.MethodGet)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(SpringWeb.HeaderXForwardedFor, "203.0.113.9")
+		req.Header.Set(SpringWeb.HeaderForwarded, "for=203.0.113.9")
+		req.Header.Set(SpringWeb.HeaderXRealIP, "203.0.113.9")
+		rec := serveEcho(req, handler, filters...)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	// 客户端直接连接时伪造的转发信息被丢弃
+	assert.Equal(t, "192.0.2.1||", serve(nil))
+
+	// 受信任的代理转发的请求保留转发信息
+	assert.Equal(t, "203.0.113.9, 192.0.2.1|for=203.0.113.9|203.0.113.9",
+		serve(SpringWeb.NewTrustedProxies("192.0.2.0/24")))
+}
+
+// toggleTransport 可以模拟上游服务不可用的 http.RoundTripper
+type toggleTransport struct {
+	down int32 // 1 表示 host 不可用
+	host string
+}
+
+func (t *toggleTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host == t.host && atomic.LoadInt32(&t.down) == 1 {
+		return nil, errors.New("connection refused")
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestProxy_HealthCheck(t *testing.T) {
+
+	newUpstream := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name))
+		}))
+	}
+
+	u1 := newUpstream("u1")
+	defer u1.Close()
+
+	u2 := newUpstream("u2")
+	defer u2.Close()
+
+	transport := &toggleTransport{host: strings.TrimPrefix(u1.URL, "http://")}
+	proxy := SpringWeb.Proxy(u1.URL, &SpringWeb.ProxyOptions{
+		Upstreams:       []string{u2.URL},
+		HealthCheckPath: "/health",
+		FailTimeout:     50 * time.Millisecond,
+		Transport:       transport,
+	})
+
+	get := func(ctx context.Context) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		rec := serveEcho(req, proxy)
+		return rec.Code, rec.Body.String()
+	}
+
+	// 客户端断开连接不会摘除上游服务
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	get(canceled)
+	_, b1 := get(context.Background())
+	_, b2 := get(context.Background())
+	assert.ElementsMatch(t, []string{"u1", "u2"}, []string{b1, b2})
+
+	// u1 不可用之后被摘除，请求全部转发到 u2
+	atomic.StoreInt32(&transport.down, 1)
+	code, _ := get(context.Background())
+	if code == http.StatusOK {
+		code, _ = get(context.Background())
+	}
+	assert.Equal(t, http.StatusBadGateway, code)
+	for i := 0; i < 3; i++ {
+		code, body := get(context.Background())
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "u2", body)
+	}
+
+	// 摘除时间到期后健康检查仍然失败，继续摘除
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		_, body := get(context.Background())
+		assert.Equal(t, "u2", body)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// u1 恢复之后通过健康检查重新加入轮询
+	atomic.StoreInt32(&transport.down, 0)
+	recovered := false
+	for i := 0; i < 100 && !recovered; i++ {
+		_, body := get(context.Background())
+		recovered = body == "u1"
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, recovered)
+}
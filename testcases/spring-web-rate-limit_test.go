@@ -0,0 +1,161 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+type errorRateLimitStore struct{}
+
+func (s *errorRateLimitStore) Allow(key string, limit SpringWeb.RateLimit) (*SpringWeb.RateLimitResult, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestRateLimitFilter(t *testing.T) {
+
+	handler := func(ctx SpringWeb.WebContext) {
+		ctx.NoContent(http.StatusOK)
+	}
+
+	serve := func(f SpringWeb.Filter, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return serveEcho(req, handler, f)
+	}
+
+	assert.Panics(t, func() { SpringWeb.NewRateLimitFilter(0, time.Second) })
+
+	for name, algorithm := range map[string]SpringWeb.RateLimitAlgorithm{
+		"token bucket":   SpringWeb.RateLimitTokenBucket,
+		"sliding window": SpringWeb.RateLimitSlidingWindow,
+	} {
+		t.Run(name, func(t *testing.T) {
+			window := 300 * time.Millisecond
+			f := SpringWeb.NewRateLimitFilter(3, window).WithAlgorithm(algorithm)
+
+			for i := 0; i < 3; i++ {
+				rec := serve(f, "10.0.0.1:1234", nil)
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+				assert.Equal(t, strconv.Itoa(2-i), rec.Header().Get("RateLimit-Remaining"))
+				assert.Equal(t, "1", rec.Header().Get("RateLimit-Reset"))
+			}
+
+			rec := serve(f, "10.0.0.1:1234", nil)
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+			assert.Equal(t, SpringWeb.MIMEApplicationProblemJSON, rec.Header().Get("Content-Type"))
+
+			// 不同的客户端使用各自的配额
+			rec = serve(f, "10.0.0.2:1234", nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			// 两个窗口之后配额完全恢复
+			time.Sleep(2 * window)
+			for i := 0; i < 3; i++ {
+				rec = serve(f, "10.0.0.1:1234", nil)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			}
+		})
+	}
+
+	t.Run("key", func(t *testing.T) {
+		f := SpringWeb.NewRateLimitFilter(1, time.Minute).
+			WithKey(SpringWeb.RateLimitByHeader("X-API-Key"))
+
+		rec := serve(f, "10.0.0.1:1234", http.Header{"X-Api-Key": {"k1"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = serve(f, "10.0.0.2:1234", http.Header{"X-Api-Key": {"k1"}})
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		rec = serve(f, "10.0.0.2:1234", http.Header{"X-Api-Key": {"k2"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// 没有 API Key 时不限流
+		for i := 0; i < 3; i++ {
+			rec = serve(f, "10.0.0.1:1234", nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+		}
+
+		f.WithKey(SpringWeb.RateLimitByPrincipal)
+		rec = serve(f, "10.0.0.3:1234", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = serve(f, "10.0.0.3:1234", nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		f := SpringWeb.NewRateLimitFilter(1, time.Minute).WithStore(&errorRateLimitStore{})
+		for i := 0; i < 3; i++ {
+			rec := serve(f, "10.0.0.1:1234", nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	s := SpringWeb.NewMemoryRateLimitStore()
+	limit := SpringWeb.RateLimit{Limit: 2, Window: 50 * time.Millisecond}
+
+	for i := 0; i < 100; i++ {
+		r, err := s.Allow(strconv.Itoa(i), limit)
+		assert.NoError(t, err)
+		assert.True(t, r.Allowed)
+	}
+	assert.Equal(t, 100, s.Len())
+
+	// 配额恢复之后的 key 会被清理
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < 100; i++ {
+		_, _ = s.Allow("new"+strconv.Itoa(i), limit)
+	}
+	assert.Equal(t, 100, s.Len())
+
+	// 滑动窗口：上一个窗口的请求按照重叠比例计入当前窗口
+	limit = SpringWeb.RateLimit{Limit: 4, Window: 200 * time.Millisecond, Algorithm: SpringWeb.RateLimitSlidingWindow}
+	for i := 0; i < 4; i++ {
+		r, _ := s.Allow("sw", limit)
+		assert.True(t, r.Allowed)
+	}
+	r, _ := s.Allow("sw", limit)
+	assert.False(t, r.Allowed)
+	assert.True(t, r.RetryAfter > 0 && r.RetryAfter <= limit.Window)
+
+	// 进入下一个窗口后上一个窗口的计数仍然占用大部分配额
+	time.Sleep(limit.Window + 20*time.Millisecond)
+	r, _ = s.Allow("sw", limit)
+	assert.False(t, r.Allowed)
+
+	time.Sleep(limit.Window / 2)
+	r, _ = s.Allow("sw", limit)
+	assert.True(t, r.Allowed)
+	assert.True(t, r.Remaining <= 1)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-echo"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/go-spring/go-spring-web/testcases"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureWriter 缓存响应体的 http.ResponseWriter
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func TestWebContext_SetResponseWriter(t *testing.T) {
+
+	var status, size int
+	var written bool
+
+	// 把处理函数的响应体转换成大写
+	upper := &testcases.FuncFilter{Fn: func(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+		w := &captureWriter{ResponseWriter: ctx.ResponseWriter(), status: http.StatusOK}
+		ctx.SetResponseWriter(w)
+		chain.Next(ctx)
+
+		status, size, written = ctx.ResponseStatus(), ctx.ResponseSize(), ctx.Written()
+
+		w.ResponseWriter.Header().Set("X-Upper", "true")
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.ResponseWriter.Write(bytes.ToUpper(w.body.Bytes()))
+	}}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.GET("/hello", func(webCtx SpringWeb.WebContext) {
+			webCtx.String(http.StatusCreated, "hello")
+		}, upper)
+
+		c.GET("/raw", func(webCtx SpringWeb.WebContext) {
+			webCtx.ResponseWriter().WriteHeader(http.StatusAccepted)
+			_, _ = webCtx.ResponseWriter().Write([]byte("raw"))
+		}, upper)
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		get := func(path string) (*http.Response, string) {
+			resp, err := http.Get("http://127.0.0.1:8080" + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			return resp, strings.TrimSpace(string(body))
+		}
+
+		resp, body := get("/hello")
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get("X-Upper"))
+		assert.Equal(t, "HELLO", body)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, 5, size)
+		assert.True(t, written)
+
+		resp, body = get("/raw")
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "RAW", body)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.Equal(t, 3, size)
+		assert.True(t, written)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureHeadersFilter(t *testing.T) {
+
+	var nonce string
+	handler := func(ctx SpringWeb.WebContext) {
+		nonce = SpringWeb.CSPNonce(ctx)
+		ctx.HTML(http.StatusOK, `<script nonce="`+nonce+`"></script>`)
+	}
+
+	f := SpringWeb.NewSecureHeadersFilter().
+		WithCSP("script-src 'self' 'nonce-{nonce}'").
+		WithPermissionsPolicy("geolocation=()")
+
+	// HTTP 请求不设置 HSTS
+	rec := serveEcho(httptest.NewRequest(http.MethodGet, "/", nil), handler, f)
+	assert.Equal(t, "", rec.Header().Get(SpringWeb.HeaderStrictTransportSecurity))
+	assert.Equal(t, "DENY", rec.Header().Get(SpringWeb.HeaderXFrameOptions))
+	assert.Equal(t, "nosniff", rec.Header().Get(SpringWeb.HeaderXContentTypeOptions))
+	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get(SpringWeb.HeaderReferrerPolicy))
+	assert.Equal(t, "geolocation=()", rec.Header().Get(SpringWeb.HeaderPermissionsPolicy))
+	assert.NotEmpty(t, nonce)
+	assert.Equal(t, "script-src 'self' 'nonce-"+nonce+"'", rec.Header().Get(SpringWeb.HeaderContentSecurityPolicy))
+
+	// 每个请求的 nonce 都不一样
+	first := nonce
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	rec = serveEcho(req, handler, f)
+	assert.NotEqual(t, first, nonce)
+	assert.Equal(t, "max-age=31536000; includeSubDomains", rec.Header().Get(SpringWeb.HeaderStrictTransportSecurity))
+
+	// 不同的路由分组使用不同的配置
+	admin := SpringWeb.NewSecureHeadersFilter().
+		WithHSTS(time.Hour, false, true).
+		WithFrameOptions("SAMEORIGIN").
+		WithReferrerPolicy("").
+		WithContentTypeNosniff(false)
+	rec = serveEcho(req, handler, admin)
+	assert.Equal(t, "max-age=3600; preload", rec.Header().Get(SpringWeb.HeaderStrictTransportSecurity))
+	assert.Equal(t, "SAMEORIGIN", rec.Header().Get(SpringWeb.HeaderXFrameOptions))
+	assert.Equal(t, "", rec.Header().Get(SpringWeb.HeaderReferrerPolicy))
+	assert.Equal(t, "", rec.Header().Get(SpringWeb.HeaderXContentTypeOptions))
+	assert.Equal(t, "", rec.Header().Get(SpringWeb.HeaderContentSecurityPolicy))
+}
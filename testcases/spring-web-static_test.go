@@ -0,0 +1,160 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testcases_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring-web/spring-echo"
+	"github.com/go-spring/go-spring-web/spring-gin"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebMapping_Static(t *testing.T) {
+
+	root, err := ioutil.TempDir("", "static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	_ = os.MkdirAll(filepath.Join(root, "docs"), os.ModePerm)
+	_ = os.MkdirAll(filepath.Join(root, "empty"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(root, "index.html"), []byte("<html>index</html>"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(root, "app.js"), []byte("console.log(1)"), os.ModePerm)
+	_ = ioutil.WriteFile(filepath.Join(root, "docs", "index.html"), []byte("<html>docs</html>"), os.ModePerm)
+
+	do := func(method string, path string, header map[string]string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, "http://127.0.0.1:8080"+path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	testRun := func(t *testing.T, c SpringWeb.WebContainer) {
+		c.SetPort(8080)
+		c.SetEnableSwagger(false)
+
+		c.Static("/static", root)
+		c.StaticFS("/spa/", SpringWeb.NewStaticFileSystem(http.Dir(root)).EnableSPA(true))
+		c.StaticFS("/list/", SpringWeb.NewStaticFileSystem(http.Dir(root)).WithIndexFiles().EnableListing(true))
+
+		// 静态文件不存在时和其他 404 使用同一个处理函数
+		c.SetNotFoundHandler(func(ctx SpringWeb.WebContext) {
+			ctx.String(http.StatusNotFound, "custom 404")
+		})
+
+		c.Start()
+		time.Sleep(time.Millisecond * 100)
+
+		defer func() {
+			c.Stop(context.TODO())
+			time.Sleep(time.Millisecond * 50)
+		}()
+
+		resp, body := do(http.MethodGet, "/static/app.js", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "console.log(1)", body)
+		assert.NotEmpty(t, resp.Header.Get("Last-Modified"))
+
+		etag := resp.Header.Get(SpringWeb.HeaderETag)
+		assert.NotEmpty(t, etag)
+
+		resp, _ = do(http.MethodGet, "/static/app.js", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		resp, body = do(http.MethodGet, "/static/app.js", map[string]string{"Range": "bytes=0-6"})
+		assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, "console", body)
+
+		resp, body = do(http.MethodHead, "/static/app.js", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "", body)
+
+		resp, body = do(http.MethodGet, "/static/", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<html>index</html>", body)
+
+		resp, body = do(http.MethodGet, "/static/docs", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<html>docs</html>", body)
+
+		// 默认不允许列出目录内容
+		resp, body = do(http.MethodGet, "/static/empty/", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "custom 404", body)
+
+		resp, body = do(http.MethodGet, "/static/none.js", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "custom 404", body)
+
+		resp, body = do(http.MethodGet, "/none", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "custom 404", body)
+
+		// 允许列出目录内容
+		resp, body = do(http.MethodGet, "/list/", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<pre>\n"+
+			"<a href=\"app.js\">app.js</a>\n"+
+			"<a href=\"docs/\">docs/</a>\n"+
+			"<a href=\"empty/\">empty/</a>\n"+
+			"<a href=\"index.html\">index.html</a>\n"+
+			"</pre>\n", body)
+
+		resp, body = do(http.MethodGet, "/list/empty/", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<pre>\n</pre>\n", body)
+
+		// 目录必须以斜杠结尾，重定向时保留查询参数
+		resp, _ = do(http.MethodGet, "/list/docs?a=1", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "/list/docs/", resp.Request.URL.Path)
+		assert.Equal(t, "a=1", resp.Request.URL.RawQuery)
+
+		resp, body = do(http.MethodGet, "/spa/pets/1", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<html>index</html>", body)
+
+		// 没有默认文件的目录也返回根目录的默认文件
+		resp, body = do(http.MethodGet, "/spa/empty/", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<html>index</html>", body)
+	}
+
+	t.Run("SpringGin", func(t *testing.T) {
+		testRun(t, SpringGin.NewContainer())
+	})
+
+	t.Run("SpringEcho", func(t *testing.T) {
+		testRun(t, SpringEcho.NewContainer())
+	})
+}
@@ -59,6 +59,14 @@ func (f *GlobalInterruptFilter) Invoke(ctx SpringWeb.WebContext, chain *SpringWe
 	}
 }
 
+type FuncFilter struct {
+	Fn func(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain)
+}
+
+func (f *FuncFilter) Invoke(ctx SpringWeb.WebContext, chain *SpringWeb.FilterChain) {
+	f.Fn(ctx, chain)
+}
+
 type NumberFilter struct {
 	l *list.List
 	n int